@@ -0,0 +1,185 @@
+package gochujang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// globalAlign is Needleman-Wunsch over two sequences of lengths n and m with
+// a linear gap score added per gap position. score(i, j) scores aligning
+// position i of the first with position j of the second. The alignment comes
+// back as index pairs in order, with -1 on the side that has a gap.
+func globalAlign(n, m int, score func(i, j int) int, gap int) [][2]int {
+	dp := make([][]int, n+1)
+	for i := range dp {
+		dp[i] = make([]int, m+1)
+		dp[i][0] = i * gap
+	}
+	for j := 0; j <= m; j++ {
+		dp[0][j] = j * gap
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			best := dp[i-1][j-1] + score(i-1, j-1)
+			if up := dp[i-1][j] + gap; up > best {
+				best = up
+			}
+			if left := dp[i][j-1] + gap; left > best {
+				best = left
+			}
+			dp[i][j] = best
+		}
+	}
+
+	var pairs [][2]int
+	i, j := n, m
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && dp[i][j] == dp[i-1][j-1]+score(i-1, j-1):
+			i--
+			j--
+			pairs = append(pairs, [2]int{i, j})
+		case i > 0 && dp[i][j] == dp[i-1][j]+gap:
+			i--
+			pairs = append(pairs, [2]int{i, -1})
+		default:
+			j--
+			pairs = append(pairs, [2]int{-1, j})
+		}
+	}
+	for l, r := 0, len(pairs)-1; l < r; l, r = l+1, r-1 {
+		pairs[l], pairs[r] = pairs[r], pairs[l]
+	}
+	return pairs
+}
+
+// AddToAlignment aligns seq to the consensus of the alignment with
+// Needleman-Wunsch and adds it, opening all-gap columns in the existing
+// sequences wherever seq has residues the consensus lacks. match, mismatch
+// and gap are added to the alignment score, so gap is usually negative.
+func (s SequenceDB) AddToAlignment(seq *Sequence, match, mismatch, gap int) (SequenceDB, error) {
+	cons, err := s.consensus()
+	if err != nil {
+		return SequenceDB{}, err
+	}
+	if _, err := s.getSequence(seq.name); err == nil {
+		return SequenceDB{}, fmt.Errorf("alignment already has a sequence named %q", seq.name)
+	}
+	alph := seq.alphabet
+	if alph == "" {
+		guess := *seq
+		guess.GuessAlphabet()
+		alph = guess.alphabet
+	}
+	if alph != s.alphabet {
+		return SequenceDB{}, &AlphabetMismatchError{Name: seq.name, Alphabet: alph, Expected: s.alphabet}
+	}
+
+	residues := ungapped(seq.sequence)
+	score := func(i, j int) int {
+		if !isGap(cons[j]) && unicode.ToUpper(rune(residues[i])) == rune(cons[j]) {
+			return match
+		}
+		return mismatch
+	}
+	pairs := globalAlign(len(residues), len(cons), score, gap)
+
+	rows := make([]strings.Builder, len(s.sequences)+1)
+	added := len(s.sequences)
+	for _, p := range pairs {
+		for k, v := range s.sequences {
+			if p[1] < 0 {
+				rows[k].WriteByte('-') // new column for an insertion in seq
+			} else {
+				rows[k].WriteByte(v.sequence[p[1]])
+			}
+		}
+		if p[0] < 0 {
+			rows[added].WriteByte('-')
+		} else {
+			rows[added].WriteByte(residues[p[0]])
+		}
+	}
+
+	var seqs []*Sequence
+	for k := range rows {
+		out := NewSequence()
+		if k == added {
+			out.name = seq.name
+		} else {
+			out.name = s.sequences[k].name
+		}
+		out.sequence = rows[k].String()
+		seqs = append(seqs, out)
+	}
+	return s.withSequences(seqs), nil
+}
+
+// LongestCommonSubstring finds the longest contiguous stretch shared exactly
+// (ignoring case) by the ungapped sequences, returned uppercased with its
+// 0-based start in each ungapped sequence. The earliest stretch in a wins
+// ties; with nothing shared it returns "", -1, -1.
+func LongestCommonSubstring(a, b *Sequence) (seq string, aPos, bPos int) {
+	x := strings.ToUpper(ungapped(a.sequence))
+	y := strings.ToUpper(ungapped(b.sequence))
+	prev := make([]int, len(y)+1) // prev[j]: length of the match ending at x[i-1], y[j-1]
+	cur := make([]int, len(y)+1)
+	best, aEnd, bEnd := 0, 0, 0
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			if x[i-1] == y[j-1] {
+				cur[j] = prev[j-1] + 1
+				if cur[j] > best {
+					best, aEnd, bEnd = cur[j], i, j
+				}
+			} else {
+				cur[j] = 0
+			}
+		}
+		prev, cur = cur, prev
+	}
+	if best == 0 {
+		return "", -1, -1
+	}
+	return x[aEnd-best : aEnd], aEnd - best, bEnd - best
+}
+
+// MergeOverlapping joins two ungapped fragments where a suffix of a
+// overlaps a prefix of b, taking the longest overlap of at least minOverlap
+// with no more than maxMismatch mismatches. As in FindBestMatch, IUPAC codes
+// match the bases they stand for unless either sequence is a protein. The
+// merged sequence keeps a's residues across the overlap and a's name; found
+// is false when no overlap qualifies.
+func MergeOverlapping(a, b *Sequence, minOverlap int, maxMismatch int) (*Sequence, bool, error) {
+	if minOverlap < 1 {
+		return nil, false, fmt.Errorf("minimum overlap must be at least 1, got %d", minOverlap)
+	}
+	if maxMismatch < 0 {
+		return nil, false, fmt.Errorf("mismatch tolerance must not be negative, got %d", maxMismatch)
+	}
+	x, y := ungapped(a.sequence), ungapped(b.sequence)
+	iupac := a.alphabet != AminoAcid && b.alphabet != AminoAcid
+	longest := len(x)
+	if len(y) < longest {
+		longest = len(y)
+	}
+	for overlap := longest; overlap >= minOverlap; overlap-- {
+		mismatches := 0
+		for i := 0; i < overlap && mismatches <= maxMismatch; i++ {
+			if !basesMatch(x[len(x)-overlap+i], y[i], iupac) {
+				mismatches++
+			}
+		}
+		if mismatches > maxMismatch {
+			continue
+		}
+		merged := NewSequence()
+		merged.name = a.name
+		merged.sequence = x + y[overlap:]
+		merged.alphabet = a.alphabet
+		merged.CalcBF()
+		return merged, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,86 @@
+package gochujang
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// binary files start with a magic string and a format version byte, followed
+// by a gob-encoded binaryDB
+const (
+	binaryMagic   = "GCJB"
+	binaryVersion = 1
+)
+
+type binaryDB struct {
+	Alphabet  DataType
+	Aligned   bool
+	Length    int
+	BF        []float64
+	Names     []string
+	Alphabets []DataType
+	Residues  []string
+}
+
+// WriteBinary serializes the DB so it can be reloaded with ReadBinary
+// without parsing the sequences again.
+func (s SequenceDB) WriteBinary(w io.Writer) error {
+	out := binaryDB{
+		Alphabet: s.alphabet,
+		Aligned:  s.aligned,
+		Length:   s.length,
+		BF:       s.BF,
+	}
+	for _, v := range s.sequences {
+		out.Names = append(out.Names, v.name)
+		out.Alphabets = append(out.Alphabets, v.alphabet)
+		out.Residues = append(out.Residues, v.sequence)
+	}
+	if _, err := io.WriteString(w, binaryMagic); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{binaryVersion}); err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(out)
+}
+
+// ReadBinary loads a DB written by WriteBinary. Per-sequence BF and GC are
+// recomputed rather than stored.
+func ReadBinary(r io.Reader) (SequenceDB, error) {
+	header := make([]byte, len(binaryMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return SequenceDB{}, err
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic {
+		return SequenceDB{}, errors.New("not a gochujang binary file")
+	}
+	if version := header[len(binaryMagic)]; version != binaryVersion {
+		return SequenceDB{}, fmt.Errorf("unsupported binary format version %d", version)
+	}
+
+	var in binaryDB
+	if err := gob.NewDecoder(r).Decode(&in); err != nil {
+		return SequenceDB{}, err
+	}
+	if len(in.Alphabets) != len(in.Names) || len(in.Residues) != len(in.Names) {
+		return SequenceDB{}, errors.New("corrupt binary file, sequence fields differ in length")
+	}
+	seqs := SequenceDB{
+		alphabet: in.Alphabet,
+		aligned:  in.Aligned,
+		length:   in.Length,
+		BF:       in.BF,
+	}
+	for i := range in.Names {
+		seq := NewSequence()
+		seq.name = in.Names[i]
+		seq.alphabet = in.Alphabets[i]
+		seq.sequence = in.Residues[i]
+		seq.CalcBF()
+		seqs.sequences = append(seqs.sequences, seq)
+	}
+	return seqs, nil
+}
@@ -0,0 +1,39 @@
+package gochujang
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	db := testDB(t, ">seq one\nACGT-A\n>seq two\nACCTTA\n")
+	var buf bytes.Buffer
+	if err := db.WriteBinary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadBinary(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.alphabet != db.alphabet || got.aligned != db.aligned || got.length != db.length || !reflect.DeepEqual(got.BF, db.BF) {
+		t.Errorf("ReadBinary DB = %v %v %d %v, want %v %v %d %v", got.alphabet, got.aligned, got.length, got.BF, db.alphabet, db.aligned, db.length, db.BF)
+	}
+	if len(got.sequences) != len(db.sequences) {
+		t.Fatalf("ReadBinary gave %d sequences, want %d", len(got.sequences), len(db.sequences))
+	}
+	for i, v := range got.sequences {
+		want := db.sequences[i]
+		if v.name != want.name || v.sequence != want.sequence || v.alphabet != want.alphabet || !reflect.DeepEqual(v.BF, want.BF) {
+			t.Errorf("sequence %d = %q %q, want %q %q", i, v.name, v.sequence, want.name, want.sequence)
+		}
+	}
+}
+
+func TestReadBinaryHeader(t *testing.T) {
+	for _, data := range []string{"FASTA", binaryMagic + "\x02", "GC"} {
+		if _, err := ReadBinary(bytes.NewBufferString(data)); err == nil {
+			t.Errorf("ReadBinary(%q) accepted", data)
+		}
+	}
+}
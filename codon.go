@@ -0,0 +1,356 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+func codonAt(idx int) string { // inverse of codonIndex
+	const bases = "TCAG"
+	return string([]byte{bases[idx/16], bases[idx/4%4], bases[idx%4]})
+}
+
+// codonCounts adds the concrete codons of a CDS read in frame 0 to counts,
+// keyed in uppercase DNA. Gap and ambiguous codons are skipped.
+func codonCounts(residues string, counts map[string]int) {
+	for i := 0; i+3 <= len(residues); i += 3 {
+		if idx := codonIndex(residues[i : i+3]); idx >= 0 {
+			counts[codonAt(idx)]++
+		}
+	}
+}
+
+// synonyms groups the sense codons of the code by the amino acid they encode.
+func (g *GeneticCode) synonyms() map[byte][]string {
+	families := make(map[byte][]string)
+	for idx := 0; idx < 64; idx++ {
+		if aa := g.aas[idx]; aa != '*' {
+			families[aa] = append(families[aa], codonAt(idx))
+		}
+	}
+	return families
+}
+
+// CAIWeights derives the relative adaptiveness of every codon from a set of
+// highly expressed genes: its count over the count of the most used codon
+// for the same amino acid. Unseen codons are given a count of 0.5, following
+// Sharp & Li (1987), and amino acids with a single codon are left out.
+func CAIWeights(ref SequenceDB, table int) (map[string]float64, error) {
+	if ref.alphabet != Nucleotide {
+		return nil, errors.New("CAI reference needs nucleotide sequences")
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, v := range ref.sequences {
+		codonCounts(v.sequence, counts)
+	}
+
+	weights := make(map[string]float64)
+	for _, family := range code.synonyms() {
+		if len(family) < 2 {
+			continue
+		}
+		most := 0
+		for _, codon := range family {
+			if counts[codon] > most {
+				most = counts[codon]
+			}
+		}
+		if most == 0 {
+			continue // amino acid never seen in the reference
+		}
+		for _, codon := range family {
+			weights[codon] = math.Max(float64(counts[codon]), 0.5) / float64(most)
+		}
+	}
+	return weights, nil
+}
+
+// CAI is the Codon Adaptation Index of a CDS read in frame 0: the geometric
+// mean of the reference weights of its codons. Stops, amino acids with a
+// single codon and codons missing from reference do not count.
+func CAI(seq *Sequence, reference map[string]float64, table int) (float64, error) {
+	if seq.alphabet != Nucleotide {
+		return 0, errors.New("CAI needs a nucleotide sequence")
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return 0, err
+	}
+	families := code.synonyms()
+	logSum := 0.0
+	n := 0
+	for i := 0; i+3 <= len(seq.sequence); i += 3 {
+		idx := codonIndex(seq.sequence[i : i+3])
+		if idx < 0 || code.aas[idx] == '*' || len(families[code.aas[idx]]) < 2 {
+			continue
+		}
+		w, exists := reference[codonAt(idx)]
+		if !exists {
+			continue
+		}
+		logSum += math.Log(w)
+		n++
+	}
+	if n == 0 {
+		return 0, errors.New("no codons of the sequence have reference weights")
+	}
+	return math.Exp(logSum / float64(n)), nil
+}
+
+// StopCodonColumns returns the codon columns (0-based, codon i spanning
+// alignment columns 3i to 3i+2) of a codon alignment at which any sequence
+// has a stop codon. Terminal stops are included, so a final column showing
+// up is usually expected.
+func (s SequenceDB) StopCodonColumns(table int) ([]int, error) {
+	if s.alphabet != Nucleotide {
+		return nil, errors.New("stop codons need a nucleotide alignment")
+	}
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	if s.length%3 != 0 {
+		return nil, fmt.Errorf("alignment length %d is not a multiple of 3", s.length)
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return nil, err
+	}
+	var cols []int
+	for c := 0; c < s.length/3; c++ {
+		for _, v := range s.sequences {
+			if code.IsStop(v.sequence[3*c : 3*c+3]) {
+				cols = append(cols, c)
+				break
+			}
+		}
+	}
+	return cols, nil
+}
+
+// OptimalCodons takes, for every amino acid with more than one codon, the
+// most used codon (all of them on a tie) in a set of highly expressed genes.
+func OptimalCodons(ref SequenceDB, table int) (map[string]bool, error) {
+	if ref.alphabet != Nucleotide {
+		return nil, errors.New("optimal codons need nucleotide sequences")
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, v := range ref.sequences {
+		codonCounts(v.sequence, counts)
+	}
+	optimal := make(map[string]bool)
+	for _, family := range code.synonyms() {
+		if len(family) < 2 {
+			continue
+		}
+		most := 0
+		for _, codon := range family {
+			if counts[codon] > most {
+				most = counts[codon]
+			}
+		}
+		for _, codon := range family {
+			if most > 0 && counts[codon] == most {
+				optimal[codon] = true
+			}
+		}
+	}
+	return optimal, nil
+}
+
+// Fop is the frequency of optimal codons in a CDS read in frame 0: optimal
+// codons over all codons for amino acids that have an optimal codon.
+func (s Sequence) Fop(optimalCodons map[string]bool, table int) (float64, error) {
+	if s.alphabet != Nucleotide {
+		return 0, errors.New("Fop needs a nucleotide sequence")
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return 0, err
+	}
+	hasOptimal := make(map[byte]bool)
+	for codon, opt := range optimalCodons {
+		if idx := codonIndex(codon); opt && idx >= 0 {
+			hasOptimal[code.aas[idx]] = true
+		}
+	}
+	optimal, total := 0, 0
+	for i := 0; i+3 <= len(s.sequence); i += 3 {
+		idx := codonIndex(s.sequence[i : i+3])
+		if idx < 0 || !hasOptimal[code.aas[idx]] {
+			continue
+		}
+		total++
+		if optimalCodons[codonAt(idx)] {
+			optimal++
+		}
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("%q has no codons for amino acids with optimal codons", s.name)
+	}
+	return float64(optimal) / float64(total), nil
+}
+
+var codonShift = [3]int{16, 4, 1} // weight of each codon position in codonIndex
+
+// synonymousSites is the Nei-Gojobori number of synonymous sites of a codon:
+// per position, the fraction of the three possible changes that keep the
+// amino acid. Changes to a stop count as nonsynonymous.
+func (g *GeneticCode) synonymousSites(idx int) float64 {
+	sites := 0.0
+	for _, shift := range codonShift {
+		digit := idx / shift % 4
+		for b := 0; b < 4; b++ {
+			if b != digit && g.aas[idx+(b-digit)*shift] == g.aas[idx] {
+				sites += 1.0 / 3
+			}
+		}
+	}
+	return sites
+}
+
+// codonDifferences splits the differences between two sense codons into
+// synonymous and nonsynonymous ones, averaged over every order of making the
+// changes that avoids passing through a stop. ok is false when every order
+// does.
+func (g *GeneticCode) codonDifferences(x, y int) (syn, nonsyn float64, ok bool) {
+	var diff []int
+	for p, shift := range codonShift {
+		if x/shift%4 != y/shift%4 {
+			diff = append(diff, p)
+		}
+	}
+	paths := 0
+	var walk func(cur int, left []int, s, n float64)
+	walk = func(cur int, left []int, s, n float64) {
+		if len(left) == 0 {
+			syn += s
+			nonsyn += n
+			paths++
+			return
+		}
+		for i, p := range left {
+			shift := codonShift[p]
+			next := cur + (y/shift%4-cur/shift%4)*shift
+			if g.aas[next] == '*' {
+				continue
+			}
+			rest := append(append([]int(nil), left[:i]...), left[i+1:]...)
+			if g.aas[next] == g.aas[cur] {
+				walk(next, rest, s+1, n)
+			} else {
+				walk(next, rest, s, n+1)
+			}
+		}
+	}
+	walk(x, diff, 0, 0)
+	if paths == 0 {
+		return 0, 0, false
+	}
+	return syn / float64(paths), nonsyn / float64(paths), true
+}
+
+// CodonDistances returns the Nei-Gojobori (1986) synonymous and
+// nonsynonymous distances between two codon-aligned CDSs, each
+// Jukes-Cantor corrected. Codons with gaps or ambiguity codes, and stops,
+// are skipped in both sequences.
+func CodonDistances(a, b *Sequence, table int) (dS, dN float64, err error) {
+	if a.alphabet != Nucleotide || b.alphabet != Nucleotide {
+		return 0, 0, errors.New("codon distances need nucleotide sequences")
+	}
+	if len(a.sequence) != len(b.sequence) {
+		return 0, 0, fmt.Errorf("sequences %q and %q are not the same length", a.name, b.name)
+	}
+	if len(a.sequence)%3 != 0 {
+		return 0, 0, fmt.Errorf("alignment length %d is not a multiple of 3", len(a.sequence))
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return 0, 0, err
+	}
+	var S, N, Sd, Nd float64
+	for i := 0; i < len(a.sequence); i += 3 {
+		x := codonIndex(a.sequence[i : i+3])
+		y := codonIndex(b.sequence[i : i+3])
+		if x < 0 || y < 0 || code.aas[x] == '*' || code.aas[y] == '*' {
+			continue
+		}
+		syn, nonsyn, ok := code.codonDifferences(x, y)
+		if !ok {
+			continue
+		}
+		s := (code.synonymousSites(x) + code.synonymousSites(y)) / 2
+		S += s
+		N += 3 - s
+		Sd += syn
+		Nd += nonsyn
+	}
+	if S == 0 || N == 0 {
+		return 0, 0, errNoOverlap
+	}
+	jc := func(p float64, kind string) (float64, error) {
+		if p >= 0.75 {
+			return 0, fmt.Errorf("%s distance between %q and %q is saturated (p = %.3f)", kind, a.name, b.name, p)
+		}
+		return -0.75 * math.Log(1-4*p/3), nil
+	}
+	if dS, err = jc(Sd/S, "synonymous"); err != nil {
+		return 0, 0, err
+	}
+	if dN, err = jc(Nd/N, "nonsynonymous"); err != nil {
+		return 0, 0, err
+	}
+	return dS, dN, nil
+}
+
+// CodonUsageComparison contrasts codon usage between two sets of CDSs read
+// in frame 0. Each codon's usage relative to its synonyms is taken in both
+// sets, with 0.5 added to every count, and the result is log2(A/B): positive
+// codons are preferred in setA. Stops and amino acids with a single codon
+// are left out.
+func CodonUsageComparison(setA, setB SequenceDB, table int) (map[string]float64, error) {
+	if setA.alphabet != Nucleotide || setB.alphabet != Nucleotide {
+		return nil, errors.New("codon usage needs nucleotide sequences")
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return nil, err
+	}
+	countsA := make(map[string]int)
+	countsB := make(map[string]int)
+	for _, v := range setA.sequences {
+		codonCounts(v.sequence, countsA)
+	}
+	for _, v := range setB.sequences {
+		codonCounts(v.sequence, countsB)
+	}
+	if len(countsA) == 0 || len(countsB) == 0 {
+		return nil, errors.New("both sets need at least one complete codon")
+	}
+
+	ratios := make(map[string]float64)
+	for _, family := range code.synonyms() {
+		if len(family) < 2 {
+			continue
+		}
+		totA, totB := 0.0, 0.0
+		for _, codon := range family {
+			totA += float64(countsA[codon]) + 0.5
+			totB += float64(countsB[codon]) + 0.5
+		}
+		for _, codon := range family {
+			fa := (float64(countsA[codon]) + 0.5) / totA
+			fb := (float64(countsB[codon]) + 0.5) / totB
+			ratios[codon] = math.Log2(fa / fb)
+		}
+	}
+	return ratios, nil
+}
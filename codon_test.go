@@ -0,0 +1,53 @@
+package gochujang
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCodonDifferences(t *testing.T) {
+	code, err := GetGeneticCode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		x, y        string
+		syn, nonsyn float64
+	}{
+		{"TTT", "TTC", 1, 0}, // Phe to Phe
+		{"AAA", "AGA", 0, 1}, // Lys to Arg
+		// TTT-CTT-CTC is nonsynonymous then synonymous, TTT-TTC-CTC the
+		// other way round
+		{"TTT", "CTC", 1, 1},
+	}
+	for _, tt := range tests {
+		syn, nonsyn, ok := code.codonDifferences(codonIndex(tt.x), codonIndex(tt.y))
+		if !ok || math.Abs(syn-tt.syn) > 1e-12 || math.Abs(nonsyn-tt.nonsyn) > 1e-12 {
+			t.Errorf("codonDifferences(%s, %s) = %g, %g, %v, want %g, %g", tt.x, tt.y, syn, nonsyn, ok, tt.syn, tt.nonsyn)
+		}
+	}
+}
+
+func TestCodonDistances(t *testing.T) {
+	// synonymous sites: ATG 0, TTT and TTC 1/3, CTG 4/3, AAA 1/3, AGA 2/3,
+	// so S = 0 + 1/3 + 4/3 + (1/3+2/3)/2 = 13/6 and N = 12 - S = 59/6, with
+	// one synonymous and one nonsynonymous difference
+	a := &Sequence{alphabet: Nucleotide, name: "a", sequence: "ATGTTTCTGAAA"}
+	b := &Sequence{alphabet: Nucleotide, name: "b", sequence: "ATGTTCCTGAGA"}
+	dS, dN, err := CodonDistances(a, b, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jc := func(p float64) float64 { return -0.75 * math.Log(1-4*p/3) }
+	if want := jc(6.0 / 13); math.Abs(dS-want) > 1e-12 {
+		t.Errorf("dS = %g, want %g", dS, want)
+	}
+	if want := jc(6.0 / 59); math.Abs(dN-want) > 1e-12 {
+		t.Errorf("dN = %g, want %g", dN, want)
+	}
+
+	same := &Sequence{alphabet: Nucleotide, name: "same", sequence: a.sequence}
+	if dS, dN, err := CodonDistances(a, same, 1); err != nil || dS != 0 || dN != 0 {
+		t.Errorf("identical CDSs: dS = %g, dN = %g, err = %v", dS, dN, err)
+	}
+}
@@ -0,0 +1,130 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrNotAligned = errors.New("sequences are not aligned")
+
+func upperByte(b byte) byte {
+	if b >= 'a' && b <= 'z' {
+		return b - 'a' + 'A'
+	}
+	return b
+}
+
+func isGap(b byte) bool {
+	return b == '-' || b == '.'
+}
+
+// stateIndex maps each residue of the alphabet (either case) to its position
+// in GetStates, so anything missing from the map is a gap or an ambiguity.
+func stateIndex(alphabet DataType) map[byte]int {
+	index := make(map[byte]int)
+	for i, st := range GetStates(alphabet) {
+		index[st[0]] = i
+		index[st[0]+'a'-'A'] = i
+	}
+	return index
+}
+
+// columnStateCounts counts, for every alignment column, the residues of each
+// state in GetStates order; gaps and ambiguity codes are not counted.
+func (s SequenceDB) columnStateCounts() ([][]int, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	nstates := len(GetStates(s.alphabet))
+	if nstates == 0 {
+		return nil, fmt.Errorf("no states defined for alphabet %q", s.alphabet)
+	}
+	index := stateIndex(s.alphabet)
+	counts := make([][]int, s.length)
+	for pos := range counts {
+		counts[pos] = make([]int, nstates)
+		for _, v := range s.sequences {
+			if i, ok := index[v.sequence[pos]]; ok {
+				counts[pos][i]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// ungappedPositions maps each column of an aligned sequence to its 0-based
+// position in the ungapped sequence, or -1 where the sequence has a gap.
+func (s Sequence) ungappedPositions() []int {
+	positions := make([]int, len(s.sequence))
+	next := 0
+	for i := 0; i < len(s.sequence); i++ {
+		if isGap(s.sequence[i]) {
+			positions[i] = -1
+		} else {
+			positions[i] = next
+			next++
+		}
+	}
+	return positions
+}
+
+func ungapped(residues string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 128 && isGap(byte(r)) {
+			return -1
+		}
+		return r
+	}, residues)
+}
+
+// selectColumns returns a copy of the alignment keeping only cols, in order.
+func (s SequenceDB) selectColumns(cols []int) SequenceDB {
+	var seqs []*Sequence
+	for _, v := range s.sequences {
+		residues := make([]byte, len(cols))
+		for i, c := range cols {
+			residues[i] = v.sequence[c]
+		}
+		seq := NewSequence()
+		seq.name = v.name
+		seq.sequence = string(residues)
+		seqs = append(seqs, seq)
+	}
+	return s.withSequences(seqs)
+}
+
+// consensus gives the majority residue of every column, ties going to the
+// state listed first by GetStates. Columns with only gaps get '-' and columns
+// with only ambiguity codes get N (nucleotides) or X.
+func (s SequenceDB) consensus() ([]byte, error) {
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	states := GetStates(s.alphabet)
+	cons := make([]byte, len(counts))
+	for pos, col := range counts {
+		best := 0
+		for i, c := range col {
+			if c > col[best] {
+				best = i
+			}
+		}
+		if col[best] > 0 {
+			cons[pos] = states[best][0]
+			continue
+		}
+		cons[pos] = '-'
+		for _, v := range s.sequences {
+			if !isGap(v.sequence[pos]) {
+				cons[pos] = 'X'
+				if s.alphabet == Nucleotide {
+					cons[pos] = 'N'
+				}
+				break
+			}
+		}
+	}
+	return cons, nil
+}
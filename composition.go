@@ -0,0 +1,313 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// GCDistribution bins the per-sequence GC content of a nucleotide DB into a
+// histogram over [0, 1]. edges holds the bins+1 bin boundaries; a GC of
+// exactly 1 falls in the last bin. Sequences without any ATGC are skipped.
+func (s SequenceDB) GCDistribution(bins int) (counts []int, edges []float64, err error) {
+	if s.alphabet != Nucleotide {
+		return nil, nil, errors.New("GC content needs nucleotide sequences")
+	}
+	if bins < 1 {
+		return nil, nil, errors.New("need at least one bin")
+	}
+	counts = make([]int, bins)
+	edges = make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = float64(i) / float64(bins)
+	}
+	for _, v := range s.sequences {
+		if math.IsNaN(v.gc) {
+			continue
+		}
+		bin := int(v.gc * float64(bins))
+		if bin == bins {
+			bin--
+		}
+		counts[bin]++
+	}
+	return counts, edges, nil
+}
+
+// CompositionHomogeneityTest is the chi-square test of base composition
+// homogeneity across taxa reported by PAUP*: a taxa by states contingency
+// table of residue counts, gaps and ambiguity codes left out. Taxa without
+// residues and states never seen are dropped and the degrees of freedom
+// shrink to match.
+func (s SequenceDB) CompositionHomogeneityTest() (chiSq float64, df int, err error) {
+	nstates := len(GetStates(s.alphabet))
+	if nstates == 0 {
+		return 0, 0, fmt.Errorf("no states defined for alphabet %q", s.alphabet)
+	}
+	index := stateIndex(s.alphabet)
+	var table [][]int
+	for _, v := range s.sequences {
+		row := make([]int, nstates)
+		tot := 0
+		for i := 0; i < len(v.sequence); i++ {
+			if st, ok := index[v.sequence[i]]; ok {
+				row[st]++
+				tot++
+			}
+		}
+		if tot > 0 {
+			table = append(table, row)
+		}
+	}
+	if len(table) < 2 {
+		return 0, 0, errors.New("need at least two taxa with residues")
+	}
+
+	rowTot := make([]int, len(table))
+	colTot := make([]int, nstates)
+	grand := 0
+	for i, row := range table {
+		for j, c := range row {
+			rowTot[i] += c
+			colTot[j] += c
+			grand += c
+		}
+	}
+	cols := 0
+	for j := range colTot {
+		if colTot[j] == 0 {
+			continue
+		}
+		cols++
+		for i, row := range table {
+			expected := float64(rowTot[i]) * float64(colTot[j]) / float64(grand)
+			d := float64(row[j]) - expected
+			chiSq += d * d / expected
+		}
+	}
+	return chiSq, (len(table) - 1) * (cols - 1), nil
+}
+
+// TransitionMatrix estimates the first-order Markov chain of the ungapped
+// sequence: P(next | current) from the counts of adjacent residues. Pairs
+// involving an ambiguity code are skipped, and residues never followed by
+// another get no row.
+func (s Sequence) TransitionMatrix() (map[string]map[string]float64, error) {
+	states := GetStates(s.alphabet)
+	if len(states) == 0 {
+		return nil, fmt.Errorf("no states defined for alphabet %q", s.alphabet)
+	}
+	index := stateIndex(s.alphabet)
+	residues := ungapped(s.sequence)
+	counts := make([][]int, len(states))
+	for i := range counts {
+		counts[i] = make([]int, len(states))
+	}
+	pairs := 0
+	for i := 0; i+1 < len(residues); i++ {
+		x, okx := index[residues[i]]
+		y, oky := index[residues[i+1]]
+		if okx && oky {
+			counts[x][y]++
+			pairs++
+		}
+	}
+	if pairs == 0 {
+		return nil, fmt.Errorf("sequence %q has no pairs of adjacent residues", s.name)
+	}
+	matrix := make(map[string]map[string]float64)
+	for x, row := range counts {
+		tot := 0
+		for _, c := range row {
+			tot += c
+		}
+		if tot == 0 {
+			continue
+		}
+		matrix[states[x]] = make(map[string]float64, len(states))
+		for y, c := range row {
+			matrix[states[x]][states[y]] = float64(c) / float64(tot)
+		}
+	}
+	return matrix, nil
+}
+
+// BFInOrder returns the sequence's base frequencies in the order of states
+// (e.g. T, C, A, G for PAML) rather than GetStates order.
+func (s Sequence) BFInOrder(states []string) ([]float64, error) {
+	own := GetStates(s.alphabet)
+	if len(s.BF) != len(own) {
+		return nil, fmt.Errorf("sequence %q has no base frequencies for alphabet %q", s.name, s.alphabet)
+	}
+	pos := make(map[string]int, len(own))
+	for i, st := range own {
+		pos[st] = i
+	}
+	bf := make([]float64, len(states))
+	for i, st := range states {
+		j, ok := pos[strings.ToUpper(st)]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a state of alphabet %q", st, s.alphabet)
+		}
+		bf[i] = s.BF[j]
+	}
+	return bf, nil
+}
+
+// bfDistance is the Euclidean distance between two frequency vectors.
+func bfDistance(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// CompositionOutliers returns the sequences whose base frequencies lie
+// unusually far from the DB-level frequencies: the Euclidean distance of
+// each sequence's BF from the DB BF is turned into a z-score over all
+// sequences, and those above zThreshold are reported in DB order. Sequences
+// without any residue are left out.
+func (s SequenceDB) CompositionOutliers(zThreshold float64) ([]string, error) {
+	var names []string
+	var dists []float64
+	for _, v := range s.sequences {
+		if len(v.BF) != len(s.BF) || math.IsNaN(v.BF[0]) {
+			continue
+		}
+		names = append(names, v.name)
+		dists = append(dists, bfDistance(v.BF, s.BF))
+	}
+	if len(dists) < 2 {
+		return nil, errors.New("need at least two sequences with residues")
+	}
+	mean := 0.0
+	for _, d := range dists {
+		mean += d
+	}
+	mean /= float64(len(dists))
+	sd := 0.0
+	for _, d := range dists {
+		sd += (d - mean) * (d - mean)
+	}
+	sd = math.Sqrt(sd / float64(len(dists)-1))
+	if sd == 0 {
+		return nil, nil // every sequence is equally far from the mean
+	}
+	var outliers []string
+	for i, d := range dists {
+		if (d-mean)/sd > zThreshold {
+			outliers = append(outliers, names[i])
+		}
+	}
+	return outliers, nil
+}
+
+// CompositionBySS pools the amino-acid frequencies (GetStates order) of the
+// columns of each secondary-structure class, given one H (helix), E (strand)
+// or C (coil) per column in ssString. Classes without residues are left out.
+func (s SequenceDB) CompositionBySS(ssString string) (map[byte][]float64, error) {
+	if s.alphabet != AminoAcid {
+		return nil, errors.New("secondary-structure composition needs a protein alignment")
+	}
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	if len(ssString) != len(counts) {
+		return nil, fmt.Errorf("structure string has %d characters, alignment has %d columns", len(ssString), len(counts))
+	}
+	pooled := make(map[byte][]int)
+	for pos, col := range counts {
+		class := upperByte(ssString[pos])
+		if class != 'H' && class != 'E' && class != 'C' {
+			return nil, fmt.Errorf("unknown structure class %q at column %d", ssString[pos], pos+1)
+		}
+		if pooled[class] == nil {
+			pooled[class] = make([]int, len(col))
+		}
+		for i, c := range col {
+			pooled[class][i] += c
+		}
+	}
+	freqs := make(map[byte][]float64)
+	for class, c := range pooled {
+		tot := 0
+		for _, n := range c {
+			tot += n
+		}
+		if tot == 0 {
+			continue
+		}
+		freqs[class] = make([]float64, len(c))
+		for i, n := range c {
+			freqs[class][i] = float64(n) / float64(tot)
+		}
+	}
+	return freqs, nil
+}
+
+// AAUsageBias compares a protein's amino-acid usage with a proteome
+// background given in GetStates order: per amino acid, log2 of its observed
+// frequency, with 0.5 added to every count, over its background frequency.
+func (s Sequence) AAUsageBias(background []float64) (map[string]float64, error) {
+	if s.alphabet != AminoAcid {
+		return nil, fmt.Errorf("amino-acid usage needs a protein sequence, %q is not one", s.name)
+	}
+	states := GetStates(AminoAcid)
+	if len(background) != len(states) {
+		return nil, fmt.Errorf("background has %d frequencies, need %d", len(background), len(states))
+	}
+	bgTot := 0.0
+	for _, f := range background {
+		if !(f > 0) {
+			return nil, errors.New("background frequencies must be positive")
+		}
+		bgTot += f
+	}
+	index := stateIndex(AminoAcid)
+	counts := make([]float64, len(states))
+	tot := 0.0
+	for i := 0; i < len(s.sequence); i++ {
+		if st, ok := index[s.sequence[i]]; ok {
+			counts[st]++
+			tot++
+		}
+	}
+	bias := make(map[string]float64, len(states))
+	for i, st := range states {
+		observed := (counts[i] + 0.5) / (tot + 0.5*float64(len(states)))
+		bias[st] = math.Log2(observed / (background[i] / bgTot))
+	}
+	return bias, nil
+}
+
+// CompositionDistanceMatrix returns the Euclidean distances between the
+// base frequencies of every pair of sequences, with the names in DB order.
+// Shared substitutions play no part, only composition. Sequences without
+// any residue have NaN distances.
+func (s SequenceDB) CompositionDistanceMatrix() ([][]float64, []string, error) {
+	if len(s.sequences) == 0 {
+		return nil, nil, errors.New("no sequences")
+	}
+	n := len(s.sequences)
+	names := make([]string, n)
+	dist := make([][]float64, n)
+	for i, v := range s.sequences {
+		names[i] = v.name
+		dist[i] = make([]float64, n)
+		if len(v.BF) != len(GetStates(s.alphabet)) {
+			return nil, nil, fmt.Errorf("sequence %q has no base frequencies for alphabet %q", v.name, s.alphabet)
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d := bfDistance(s.sequences[i].BF, s.sequences[j].BF)
+			dist[i][j] = d
+			dist[j][i] = d
+		}
+	}
+	return dist, names, nil
+}
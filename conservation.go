@@ -0,0 +1,232 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ConservationJS scores every column by the Jensen-Shannon divergence (in
+// bits) between its residue distribution and a background distribution, as
+// in Capra & Singh (2007). Scores are weighted by the fraction of sequences
+// with a residue at the column, so gappy columns score lower. A nil
+// background uses the DB-level BF.
+func (s SequenceDB) ConservationJS(background []float64) ([]float64, error) {
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	q, err := s.normalizeBackground(background)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]float64, len(counts))
+	p := make([]float64, len(q))
+	for pos, col := range counts {
+		tot := 0
+		for _, c := range col {
+			tot += c
+		}
+		if tot == 0 {
+			continue // all gaps, nothing conserved
+		}
+		for i, c := range col {
+			p[i] = float64(c) / float64(tot)
+		}
+		js := 0.0
+		for i := range p {
+			m := 0.5 * (p[i] + q[i])
+			if p[i] > 0 {
+				js += 0.5 * p[i] * math.Log2(p[i]/m)
+			}
+			if q[i] > 0 {
+				js += 0.5 * q[i] * math.Log2(q[i]/m)
+			}
+		}
+		scores[pos] = js * float64(tot) / float64(len(s.sequences))
+	}
+	return scores, nil
+}
+
+// normalizeBackground checks a background frequency vector against the DB
+// alphabet and rescales it to sum to one, falling back to the DB-level BF.
+func (s SequenceDB) normalizeBackground(background []float64) ([]float64, error) {
+	if background == nil {
+		background = s.BF
+	}
+	nstates := len(GetStates(s.alphabet))
+	if len(background) != nstates {
+		return nil, fmt.Errorf("background has %d frequencies, alphabet %q has %d states", len(background), s.alphabet, nstates)
+	}
+	tot := 0.0
+	for _, f := range background {
+		if f < 0 || math.IsNaN(f) {
+			return nil, errors.New("background frequencies must be non-negative")
+		}
+		tot += f
+	}
+	if tot == 0 {
+		return nil, errors.New("background frequencies sum to zero")
+	}
+	q := make([]float64, nstates)
+	for i, f := range background {
+		q[i] = f / tot
+	}
+	return q, nil
+}
+
+// WriteConservationWig writes the ConservationJS scores as a WIG track on
+// the ungapped coordinates of the reference sequence refName, on the chrom
+// named by its first word. Columns where the reference has a gap have no
+// coordinate and are left out.
+func (s SequenceDB) WriteConservationWig(w io.Writer, refName string) error {
+	ref, err := s.getSequence(refName)
+	if err != nil {
+		return err
+	}
+	scores, err := s.ConservationJS(nil)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "track type=wiggle_0 name=\"conservation\"\nfixedStep chrom=%s start=1 step=1\n", seqID(refName)); err != nil {
+		return err
+	}
+	// every ungapped reference position owns exactly one column, so the
+	// track is a single contiguous fixedStep block
+	for col, pos := range ref.ungappedPositions() {
+		if pos < 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%.6g\n", scores[col]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SiteVariabilityScores gives every column the minimum number of changes it
+// needs on a star tree (distinct residues minus one), divided by the most it
+// could need given its number of residues (residues minus one). Scores run
+// from 0 for invariant columns to 1 when every residue differs; columns with
+// fewer than two residues score 0.
+func (s SequenceDB) SiteVariabilityScores() ([]float64, error) {
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(counts))
+	for pos, col := range counts {
+		distinct, n := 0, 0
+		for _, c := range col {
+			if c > 0 {
+				distinct++
+			}
+			n += c
+		}
+		if n > 1 {
+			scores[pos] = float64(distinct-1) / float64(n-1)
+		}
+	}
+	return scores, nil
+}
+
+// ConsensusAgreement returns the consensus residue of every column and the
+// fraction of all sequences, gapped ones included, carrying it. Columns
+// without any residue of the alphabet get a consensus of '-', N or X and an
+// agreement of 0.
+func (s SequenceDB) ConsensusAgreement() ([]float64, []byte, error) {
+	cons, err := s.consensus()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(s.sequences) == 0 {
+		return nil, nil, errors.New("alignment is empty")
+	}
+	index := stateIndex(s.alphabet)
+	agreement := make([]float64, len(cons))
+	for i, c := range cons {
+		want, ok := index[c]
+		if !ok {
+			continue
+		}
+		n := 0
+		for _, v := range s.sequences {
+			if st, ok := index[v.sequence[i]]; ok && st == want {
+				n++
+			}
+		}
+		agreement[i] = float64(n) / float64(len(s.sequences))
+	}
+	return agreement, cons, nil
+}
+
+// InvariantColumns returns the columns whose residues are all the same
+// state. Gaps and ambiguity codes are ignored, and columns without any
+// residue are not counted as invariant.
+func (s SequenceDB) InvariantColumns() ([]int, error) {
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	var cols []int
+	for pos, col := range counts {
+		distinct := 0
+		for _, c := range col {
+			if c > 0 {
+				distinct++
+			}
+		}
+		if distinct == 1 {
+			cols = append(cols, pos)
+		}
+	}
+	return cols, nil
+}
+
+// ProportionInvariant is the fraction of columns that are invariant, the
+// usual starting value for the +I parameter of a substitution model.
+func (s SequenceDB) ProportionInvariant() (float64, error) {
+	cols, err := s.InvariantColumns()
+	if err != nil {
+		return 0, err
+	}
+	if s.length == 0 {
+		return 0, errors.New("alignment is empty")
+	}
+	return float64(len(cols)) / float64(s.length), nil
+}
+
+// ColumnRelativeEntropy scores every column of a protein alignment by the
+// Kullback-Leibler divergence (in bits) of its amino-acid distribution from
+// background, given in GetStates order; a nil background uses the DB-level
+// BF. Columns without residues score 0, and a residue the background gives
+// zero frequency makes its column +Inf.
+func (s SequenceDB) ColumnRelativeEntropy(background []float64) ([]float64, error) {
+	if s.alphabet != AminoAcid {
+		return nil, errors.New("relative entropy needs a protein alignment")
+	}
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	q, err := s.normalizeBackground(background)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(counts))
+	for pos, col := range counts {
+		tot := 0
+		for _, c := range col {
+			tot += c
+		}
+		for i, c := range col {
+			if c > 0 {
+				p := float64(c) / float64(tot)
+				scores[pos] += p * math.Log2(p/q[i])
+			}
+		}
+	}
+	return scores, nil
+}
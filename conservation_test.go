@@ -0,0 +1,22 @@
+package gochujang
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteConservationWig(t *testing.T) {
+	db := testDB(t, ">chr1 assembled contig\nAC-GT\n>s1\nACAGT\n")
+	var buf bytes.Buffer
+	if err := db.WriteConservationWig(&buf, "chr1 assembled contig"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if want := "fixedStep chrom=chr1 start=1 step=1"; len(lines) < 2 || lines[1] != want {
+		t.Fatalf("WriteConservationWig header = %q, want %q", lines, want)
+	}
+	if got := len(lines) - 2; got != 4 {
+		t.Errorf("got %d scores, want one per reference base (4)", got)
+	}
+}
@@ -0,0 +1,50 @@
+package gochujang
+
+// Coverage returns, for every alignment column, how many sequences have a
+// non-gap character there.
+func (s SequenceDB) Coverage() ([]int, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	depth := make([]int, s.length)
+	for _, v := range s.sequences {
+		for i := 0; i < len(v.sequence); i++ {
+			if !isGap(v.sequence[i]) {
+				depth[i]++
+			}
+		}
+	}
+	return depth, nil
+}
+
+// LowCoverageRegions returns the [start, end) ranges, on the 0-based
+// ungapped coordinates of refName, where the Coverage depth is below
+// minDepth. The reference counts toward the depth like any other sequence.
+func (s SequenceDB) LowCoverageRegions(refName string, minDepth int) ([][2]int, error) {
+	ref, err := s.getSequence(refName)
+	if err != nil {
+		return nil, err
+	}
+	depth, err := s.Coverage()
+	if err != nil {
+		return nil, err
+	}
+	var regions [][2]int
+	start := -1
+	for col, pos := range ref.ungappedPositions() {
+		if pos < 0 {
+			continue
+		}
+		low := depth[col] < minDepth
+		if low && start < 0 {
+			start = pos
+		} else if !low && start >= 0 {
+			regions = append(regions, [2]int{start, pos})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		regions = append(regions, [2]int{start, len(ungapped(ref.sequence))})
+	}
+	return regions, nil
+}
@@ -0,0 +1,29 @@
+package gochujang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLowCoverageRegions(t *testing.T) {
+	// depth per column: 3 3 1 2 2 1; the reference gap at column 3 has no
+	// coordinate
+	db := testDB(t, ">ref\nACG-TA\n>s1\nAC-GT-\n>s2\nAC-G--\n")
+	tests := []struct {
+		minDepth int
+		want     [][2]int
+	}{
+		{1, nil},
+		{2, [][2]int{{2, 3}, {4, 5}}},
+		{3, [][2]int{{2, 5}}},
+	}
+	for _, tt := range tests {
+		got, err := db.LowCoverageRegions("ref", tt.minDepth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LowCoverageRegions(%d) = %v, want %v", tt.minDepth, got, tt.want)
+		}
+	}
+}
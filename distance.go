@@ -0,0 +1,331 @@
+package gochujang
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var errNoOverlap = errors.New("sequences share no comparable sites")
+
+// comparableSites counts the sites where both sequences have a residue of
+// their alphabet (gaps and ambiguity codes are skipped pairwise) and how
+// many of those sites differ.
+func comparableSites(a, b *Sequence) (sites, diffs int, err error) {
+	if len(a.sequence) != len(b.sequence) {
+		return 0, 0, fmt.Errorf("sequences %q and %q are not the same length", a.name, b.name)
+	}
+	if a.alphabet != b.alphabet {
+		return 0, 0, &AlphabetMismatchError{Name: b.name, Alphabet: b.alphabet, Expected: a.alphabet}
+	}
+	index := stateIndex(a.alphabet)
+	for i := 0; i < len(a.sequence); i++ {
+		x, okx := index[a.sequence[i]]
+		y, oky := index[b.sequence[i]]
+		if okx && oky {
+			sites++
+			if x != y {
+				diffs++
+			}
+		}
+	}
+	return sites, diffs, nil
+}
+
+// OverlapSites counts the positions where neither aligned sequence has a
+// gap. Ambiguity codes count as data here, unlike in the distances.
+func OverlapSites(a, b *Sequence) (int, error) {
+	if len(a.sequence) != len(b.sequence) {
+		return 0, fmt.Errorf("sequences %q and %q are not the same length", a.name, b.name)
+	}
+	n := 0
+	for i := 0; i < len(a.sequence); i++ {
+		if !isGap(a.sequence[i]) && !isGap(b.sequence[i]) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// PDistance is the proportion of comparable sites that differ.
+func PDistance(a, b *Sequence) (float64, error) {
+	sites, diffs, err := comparableSites(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if sites == 0 {
+		return 0, errNoOverlap
+	}
+	return float64(diffs) / float64(sites), nil
+}
+
+// JCDistance is the Jukes-Cantor corrected distance, generalised to the
+// number of states in the alphabet so it also covers proteins. Pairs too
+// divergent for the correction get +Inf.
+func JCDistance(a, b *Sequence) (float64, error) {
+	p, err := PDistance(a, b)
+	if err != nil {
+		return 0, err
+	}
+	k := float64(len(GetStates(a.alphabet)))
+	frac := 1 - 1/k
+	if p >= frac {
+		return math.Inf(1), nil
+	}
+	return -frac * math.Log(1-p/frac), nil
+}
+
+// K2PDistance is the Kimura two-parameter distance between two nucleotide
+// sequences. Pairs too divergent for the correction get +Inf.
+func K2PDistance(a, b *Sequence) (float64, error) {
+	if a.alphabet != Nucleotide || b.alphabet != Nucleotide {
+		return 0, errors.New("K2P distance needs nucleotide sequences")
+	}
+	sites, _, err := comparableSites(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if sites == 0 {
+		return 0, errNoOverlap
+	}
+	index := stateIndex(Nucleotide)
+	ts, tv := 0, 0
+	for i := 0; i < len(a.sequence); i++ {
+		x, okx := index[a.sequence[i]]
+		y, oky := index[b.sequence[i]]
+		if !okx || !oky || x == y {
+			continue
+		}
+		if x%2 == y%2 { // A,G and T,C sit at even and odd indices of GetStates
+			ts++
+		} else {
+			tv++
+		}
+	}
+	P := float64(ts) / float64(sites)
+	Q := float64(tv) / float64(sites)
+	if 1-2*P-Q <= 0 || 1-2*Q <= 0 {
+		return math.Inf(1), nil
+	}
+	return -0.5*math.Log(1-2*P-Q) - 0.25*math.Log(1-2*Q), nil
+}
+
+// LogDetDistance is the LogDet distance in Lake's paralinear form, computed
+// from the 4x4 divergence matrix of the sites where both nucleotide
+// sequences have a base. It stays consistent when base composition differs
+// between the two.
+func LogDetDistance(a, b *Sequence) (float64, error) {
+	if a.alphabet != Nucleotide || b.alphabet != Nucleotide {
+		return 0, errors.New("LogDet distance needs nucleotide sequences")
+	}
+	sites, _, err := comparableSites(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if sites == 0 {
+		return 0, errNoOverlap
+	}
+	index := stateIndex(Nucleotide)
+	var f [4][4]float64
+	for i := 0; i < len(a.sequence); i++ {
+		x, okx := index[a.sequence[i]]
+		y, oky := index[b.sequence[i]]
+		if okx && oky {
+			f[x][y] += 1 / float64(sites)
+		}
+	}
+	detF := det4(f)
+	detA, detB := 1.0, 1.0
+	for i := 0; i < 4; i++ {
+		rowSum, colSum := 0.0, 0.0
+		for j := 0; j < 4; j++ {
+			rowSum += f[i][j]
+			colSum += f[j][i]
+		}
+		detA *= rowSum
+		detB *= colSum
+	}
+	if detF <= 0 || detA <= 0 || detB <= 0 {
+		return 0, fmt.Errorf("LogDet distance undefined for %q and %q, divergence matrix determinant is not positive", a.name, b.name)
+	}
+	return -0.25 * (math.Log(detF) - 0.5*(math.Log(detA)+math.Log(detB))), nil
+}
+
+// det4 is the determinant of a 4x4 matrix by Gaussian elimination with
+// partial pivoting.
+func det4(m [4][4]float64) float64 {
+	det := 1.0
+	for c := 0; c < 4; c++ {
+		p := c
+		for r := c + 1; r < 4; r++ {
+			if math.Abs(m[r][c]) > math.Abs(m[p][c]) {
+				p = r
+			}
+		}
+		if m[p][c] == 0 {
+			return 0
+		}
+		if p != c {
+			m[p], m[c] = m[c], m[p]
+			det = -det
+		}
+		det *= m[c][c]
+		for r := c + 1; r < 4; r++ {
+			k := m[r][c] / m[c][c]
+			for j := c; j < 4; j++ {
+				m[r][j] -= k * m[c][j]
+			}
+		}
+	}
+	return det
+}
+
+func pairwiseDistance(a, b *Sequence, model string) (float64, error) {
+	switch model {
+	case "p":
+		return PDistance(a, b)
+	case "jc":
+		return JCDistance(a, b)
+	case "k2p":
+		return K2PDistance(a, b)
+	case "logdet":
+		return LogDetDistance(a, b)
+	default:
+		return 0, fmt.Errorf("unknown distance model %q", model)
+	}
+}
+
+// DistanceMatrix returns pairwise distances between all sequences, in DB
+// order, under model "p", "jc", "k2p" or "logdet". Pairs sharing no
+// comparable site get NaN.
+func (s SequenceDB) DistanceMatrix(model string) ([][]float64, error) {
+	return s.distanceMatrix(model, 0)
+}
+
+// DistanceMatrixStrict is DistanceMatrix but fails when any pair shares
+// fewer than minOverlap comparable sites instead of returning NaN.
+func (s SequenceDB) DistanceMatrixStrict(model string, minOverlap int) ([][]float64, error) {
+	return s.distanceMatrix(model, minOverlap)
+}
+
+func (s SequenceDB) distanceMatrix(model string, minOverlap int) ([][]float64, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	n := len(s.sequences)
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			a, b := s.sequences[i], s.sequences[j]
+			sites, _, err := comparableSites(a, b)
+			if err != nil {
+				return nil, fmt.Errorf("%s vs %s: %w", a.name, b.name, err)
+			}
+			if sites < minOverlap {
+				return nil, fmt.Errorf("%s vs %s: only %d comparable sites, need %d", a.name, b.name, sites, minOverlap)
+			}
+			d := math.NaN()
+			if sites > 0 {
+				if d, err = pairwiseDistance(a, b, model); err != nil {
+					return nil, fmt.Errorf("%s vs %s: %w", a.name, b.name, err)
+				}
+			}
+			dist[i][j] = d
+			dist[j][i] = d
+		}
+	}
+	return dist, nil
+}
+
+// TotalDivergence is the sum of the distances between every pair of
+// sequences under the given model.
+func (s SequenceDB) TotalDivergence(model string) (float64, error) {
+	dist, err := s.DistanceMatrixStrict(model, 1)
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for i := range dist {
+		for j := i + 1; j < len(dist); j++ {
+			total += dist[i][j]
+		}
+	}
+	return total, nil
+}
+
+// WriteWeightedDistances writes the distance matrix under model as a
+// lower-triangular PHYLIP matrix, then a blank line and a matrix of the same
+// shape holding the number of sites each distance was computed from.
+func (s SequenceDB) WriteWeightedDistances(w io.Writer, model string) error {
+	dist, err := s.DistanceMatrixStrict(model, 1)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d\n", len(s.sequences))
+	for i, v := range s.sequences {
+		bw.WriteString(v.name)
+		for j := 0; j < i; j++ {
+			fmt.Fprintf(bw, " %.6f", dist[i][j])
+		}
+		bw.WriteString("\n")
+	}
+	fmt.Fprintf(bw, "\n%d\n", len(s.sequences))
+	for i, v := range s.sequences {
+		bw.WriteString(v.name)
+		for j := 0; j < i; j++ {
+			sites, _, err := comparableSites(v, s.sequences[j])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(bw, " %d", sites)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// RelativeRates scores each ingroup taxon by its mean Jukes-Cantor
+// distance to all other taxa, divided by the same mean for the outgroup.
+// Taxa scoring well above the rest sit on long branches and are
+// long-branch attraction suspects.
+func (s SequenceDB) RelativeRates(outgroupName string) (map[string]float64, error) {
+	out, err := s.getSequence(outgroupName)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.sequences) < 2 {
+		return nil, errors.New("need at least one ingroup taxon")
+	}
+	dist, err := s.DistanceMatrixStrict("jc", 1)
+	if err != nil {
+		return nil, err
+	}
+	rowMean := func(i int) float64 {
+		sum := 0.0
+		for j := range dist[i] {
+			sum += dist[i][j] // the diagonal is 0
+		}
+		return sum / float64(len(dist)-1)
+	}
+	o := 0
+	for s.sequences[o] != out {
+		o++
+	}
+	outMean := rowMean(o)
+	if outMean == 0 {
+		return nil, errors.New("outgroup is identical to every other taxon")
+	}
+	rates := make(map[string]float64, len(s.sequences)-1)
+	for i, v := range s.sequences {
+		if i != o {
+			rates[v.name] = rowMean(i) / outMean
+		}
+	}
+	return rates, nil
+}
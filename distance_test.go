@@ -0,0 +1,111 @@
+package gochujang
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistances(t *testing.T) {
+	// 20 sites: 2 transitions (A<->G, C<->T), 2 transversions, and a gapped
+	// and an ambiguous site that are not compared
+	db := testDB(t, ">a\nACGTACGTACGTACGTACGTAC\n>b\nGTGTACGTACGTAAGTACTT-N\n")
+	a, b := db.sequences[0], db.sequences[1]
+
+	p, err := PDistance(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != 0.2 {
+		t.Errorf("PDistance = %g, want 0.2", p)
+	}
+
+	jc, err := JCDistance(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJC := -0.75 * math.Log(1-4.0/3*0.2)
+	if math.Abs(jc-wantJC) > 1e-12 {
+		t.Errorf("JCDistance = %g, want %g", jc, wantJC)
+	}
+
+	k2p, err := K2PDistance(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P, Q := 0.1, 0.1
+	wantK2P := -0.5*math.Log(1-2*P-Q) - 0.25*math.Log(1-2*Q)
+	if math.Abs(k2p-wantK2P) > 1e-12 {
+		t.Errorf("K2PDistance = %g, want %g", k2p, wantK2P)
+	}
+
+	sat := testDB(t, ">a\nAAAA\n>b\nCGTC\n")
+	if d, _ := JCDistance(sat.sequences[0], sat.sequences[1]); !math.IsInf(d, 1) {
+		t.Errorf("saturated JCDistance = %g, want +Inf", d)
+	}
+}
+
+func TestClusterUPGMA(t *testing.T) {
+	// a-b differ at 1 of 20 sites (p = 0.05), c differs from both at 8
+	db := testDB(t, ">a\nACGTACGTACGTACGTACGT\n>b\nACGTACGTACGTACGTACGA\n>c\nTGCAACGTTGCAACGTACGT\n")
+	for _, tt := range []struct {
+		threshold float64
+		together  bool
+	}{
+		{0.05, true}, // the threshold is a distance, not a node height
+		{0.04, false},
+	} {
+		groups, err := db.ClusterUPGMA(tt.threshold, "p")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (groups["a"] == groups["b"]) != tt.together {
+			t.Errorf("threshold %g: a and b together = %v, want %v", tt.threshold, groups["a"] == groups["b"], tt.together)
+		}
+		if groups["a"] == groups["c"] {
+			t.Errorf("threshold %g: c should be on its own", tt.threshold)
+		}
+	}
+}
+
+func TestLogDetDistance(t *testing.T) {
+	// every base pairs twice with itself and once with each other base, so
+	// the divergence matrix is the symmetric uniform one on which LogDet
+	// reduces to Jukes-Cantor: p = 12/20
+	db := testDB(t, ">a\nAAAAACCCCCGGGGGTTTTT\n>b\nAACGTCCAGTGGACTTTACG\n")
+	d, err := LogDetDistance(db.sequences[0], db.sequences[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := -0.75 * math.Log(1-4.0/3*0.6)
+	if math.Abs(d-want) > 1e-12 {
+		t.Errorf("LogDetDistance = %g, want %g", d, want)
+	}
+
+	missing := testDB(t, ">a\nAACCGGAACC\n>b\nAACCGGTTCC\n") // no T in a
+	if _, err := LogDetDistance(missing.sequences[0], missing.sequences[1]); err == nil {
+		t.Error("singular divergence matrix should give an error")
+	}
+}
+
+func TestRelativeRates(t *testing.T) {
+	// p distances over 10 sites: a-b 0.1, a-out 0.2, b-out 0.3
+	db := testDB(t, ">out\nAAAAAAAAAA\n>a\nCCAAAAAAAA\n>b\nCCCAAAAAAA\n")
+	rates, err := db.RelativeRates("out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jc := func(p float64) float64 { return -0.75 * math.Log(1-4.0/3*p) }
+	outMean := (jc(0.2) + jc(0.3)) / 2
+	want := map[string]float64{
+		"a": (jc(0.1) + jc(0.2)) / 2 / outMean,
+		"b": (jc(0.1) + jc(0.3)) / 2 / outMean,
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("RelativeRates = %v, want %v", rates, want)
+	}
+	for name, w := range want {
+		if math.Abs(rates[name]-w) > 1e-12 {
+			t.Errorf("rate of %s = %g, want %g", name, rates[name], w)
+		}
+	}
+}
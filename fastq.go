@@ -0,0 +1,104 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FastqRecord is one FASTQ read, with Phred+33 encoded qualities.
+type FastqRecord struct {
+	Name     string
+	Sequence string
+	Quality  string
+}
+
+const maxConsensusQuality = 60
+
+// ConsensusFastq collapses equal-length, already aligned reads into one
+// read. Each position takes the base with the highest summed quality, and
+// its quality is that sum minus the qualities of the reads disagreeing with
+// it, kept within 0 to 60. Gaps and ambiguity codes add no support;
+// positions without any base get N at quality 0.
+func ConsensusFastq(records []FastqRecord) (*FastqRecord, error) {
+	if len(records) == 0 {
+		return nil, errors.New("no reads to collapse")
+	}
+	length := len(records[0].Sequence)
+	for _, r := range records {
+		if len(r.Sequence) != len(r.Quality) {
+			return nil, fmt.Errorf("read %q has %d bases but %d qualities", r.Name, len(r.Sequence), len(r.Quality))
+		}
+		if len(r.Sequence) != length {
+			return nil, fmt.Errorf("read %q is %d long, expected %d", r.Name, len(r.Sequence), length)
+		}
+	}
+
+	states := GetStates(Nucleotide)
+	index := stateIndex(Nucleotide)
+	seq := make([]byte, length)
+	qual := make([]byte, length)
+	for i := 0; i < length; i++ {
+		support := make([]int, len(states))
+		total := 0
+		for _, r := range records {
+			q := int(r.Quality[i]) - 33
+			if q < 0 {
+				return nil, fmt.Errorf("read %q has quality %q below Phred+33 range", r.Name, r.Quality[i])
+			}
+			if st, ok := index[r.Sequence[i]]; ok {
+				support[st] += q
+				total += q
+			}
+		}
+		best := 0
+		for st := range support {
+			if support[st] > support[best] {
+				best = st
+			}
+		}
+		q := 0
+		seq[i] = 'N'
+		if total > 0 {
+			seq[i] = states[best][0]
+			q = 2*support[best] - total
+			if q < 0 {
+				q = 0
+			}
+			if q > maxConsensusQuality {
+				q = maxConsensusQuality
+			}
+		}
+		qual[i] = byte(q + 33)
+	}
+	return &FastqRecord{Name: "consensus", Sequence: string(seq), Quality: string(qual)}, nil
+}
+
+// mateName is the part of a read name shared by both mates: the first
+// whitespace-separated field without a trailing /1 or /2.
+func mateName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		name = fields[0]
+	}
+	if strings.HasSuffix(name, "/1") || strings.HasSuffix(name, "/2") {
+		name = name[:len(name)-2]
+	}
+	return name
+}
+
+// Deinterleave splits interleaved paired reads into first and second mates,
+// checking that each pair of consecutive records names the same fragment.
+func Deinterleave(records []FastqRecord) (r1, r2 []FastqRecord, err error) {
+	if len(records)%2 != 0 {
+		return nil, nil, fmt.Errorf("odd number of records (%d), cannot pair them", len(records))
+	}
+	for i := 0; i < len(records); i += 2 {
+		a, b := records[i], records[i+1]
+		if mateName(a.Name) != mateName(b.Name) {
+			return nil, nil, fmt.Errorf("records %d and %d are not mates: %q and %q", i+1, i+2, a.Name, b.Name)
+		}
+		r1 = append(r1, a)
+		r2 = append(r2, b)
+	}
+	return r1, r2, nil
+}
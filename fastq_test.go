@@ -0,0 +1,60 @@
+package gochujang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConsensusFastq(t *testing.T) {
+	// Phred+33: '5' is 20, '?' 30, 'I' 40, '!' 0 and ']' the cap of 60
+	records := []FastqRecord{
+		{Name: "r1", Sequence: "ACGTN", Quality: "IIIII"},
+		{Name: "r2", Sequence: "ACGA-", Quality: "55555"},
+		{Name: "r3", Sequence: "ATG-N", Quality: "?????"},
+	}
+	got, err := ConsensusFastq(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A 90 capped; C 60 against T 30; G capped; T 40 against A 20; no base
+	want := &FastqRecord{Name: "consensus", Sequence: "ACGTN", Quality: "]?]5!"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConsensusFastq = %+v, want %+v", got, want)
+	}
+
+	for _, bad := range [][]FastqRecord{
+		nil,
+		{{Name: "r1", Sequence: "ACGT", Quality: "III"}},
+		{{Name: "r1", Sequence: "ACGT", Quality: "IIII"}, {Name: "r2", Sequence: "ACG", Quality: "III"}},
+		{{Name: "r1", Sequence: "ACGT", Quality: "II I"}},
+	} {
+		if _, err := ConsensusFastq(bad); err == nil {
+			t.Errorf("ConsensusFastq(%+v) accepted", bad)
+		}
+	}
+}
+
+func TestDeinterleave(t *testing.T) {
+	records := []FastqRecord{
+		{Name: "frag1/1", Sequence: "AC", Quality: "II"},
+		{Name: "frag1/2", Sequence: "GT", Quality: "II"},
+		{Name: "frag2 1:N:0", Sequence: "AA", Quality: "II"},
+		{Name: "frag2 2:N:0", Sequence: "TT", Quality: "II"},
+	}
+	r1, r2, err := Deinterleave(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []FastqRecord{records[0], records[2]}; !reflect.DeepEqual(r1, want) {
+		t.Errorf("first mates = %+v, want %+v", r1, want)
+	}
+	if want := []FastqRecord{records[1], records[3]}; !reflect.DeepEqual(r2, want) {
+		t.Errorf("second mates = %+v, want %+v", r2, want)
+	}
+
+	for _, bad := range [][]FastqRecord{records[:3], {records[0], records[3]}} {
+		if _, _, err := Deinterleave(bad); err == nil {
+			t.Errorf("Deinterleave accepted %d unpaired records", len(bad))
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package gochujang
+
+import "fmt"
+
+// Dereplicate keeps one sequence out of every set of identical sequences.
+// keep picks the surviving name: "first" (in DB order), "shortest-name" or
+// "longest-name", with ties going to the earlier sequence. Representatives
+// come out in the order their set first appears, and absorbed maps each
+// representative that swallowed duplicates to the names it replaced.
+func (s SequenceDB) Dereplicate(keep string) (SequenceDB, map[string][]string, error) {
+	if keep != "first" && keep != "shortest-name" && keep != "longest-name" {
+		return SequenceDB{}, nil, fmt.Errorf("unknown keep rule %q", keep)
+	}
+
+	var order []string // distinct sequences by first appearance
+	sets := make(map[string][]*Sequence)
+	for _, v := range s.sequences {
+		if _, seen := sets[v.sequence]; !seen {
+			order = append(order, v.sequence)
+		}
+		sets[v.sequence] = append(sets[v.sequence], v)
+	}
+
+	var seqs []*Sequence
+	absorbed := make(map[string][]string)
+	for _, residues := range order {
+		set := sets[residues]
+		rep := set[0]
+		for _, v := range set[1:] {
+			if (keep == "shortest-name" && len(v.name) < len(rep.name)) ||
+				(keep == "longest-name" && len(v.name) > len(rep.name)) {
+				rep = v
+			}
+		}
+		for _, v := range set {
+			if v != rep {
+				absorbed[rep.name] = append(absorbed[rep.name], v.name)
+			}
+		}
+		seq := NewSequence()
+		seq.name = rep.name
+		seq.sequence = rep.sequence
+		seqs = append(seqs, seq)
+	}
+	return s.withSequences(seqs), absorbed, nil
+}
+
+// MinimalDiversitySubset greedily picks taxa until every variable column
+// shows at least two different residues among them. It starts from the pair
+// differing at the most variable columns, then keeps adding the taxon that
+// completes the most columns (ties go to the one giving a first residue to
+// the most still-empty columns, then to DB order). Names come out in the
+// order picked; nil when the alignment has no variable columns.
+func (s SequenceDB) MinimalDiversitySubset() ([]string, error) {
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	index := stateIndex(s.alphabet)
+	var variable []int
+	for col, c := range counts {
+		distinct := 0
+		for _, n := range c {
+			if n > 0 {
+				distinct++
+			}
+		}
+		if distinct > 1 {
+			variable = append(variable, col)
+		}
+	}
+	if len(variable) == 0 {
+		return nil, nil
+	}
+	states := make([][]int, len(s.sequences)) // per taxon per variable column, -1 if no residue
+	for t, v := range s.sequences {
+		states[t] = make([]int, len(variable))
+		for j, col := range variable {
+			states[t][j] = -1
+			if st, ok := index[v.sequence[col]]; ok {
+				states[t][j] = st
+			}
+		}
+	}
+
+	first := make([]int, len(variable)) // first residue seen among the picked taxa
+	covered := make([]bool, len(variable))
+	for j := range first {
+		first[j] = -1
+	}
+	chosen := make([]bool, len(s.sequences))
+	var names []string
+	pick := func(t int) (newlyCovered int) {
+		chosen[t] = true
+		names = append(names, s.sequences[t].name)
+		for j, st := range states[t] {
+			if st < 0 || covered[j] {
+				continue
+			}
+			if first[j] < 0 {
+				first[j] = st
+			} else if st != first[j] {
+				covered[j] = true
+				newlyCovered++
+			}
+		}
+		return newlyCovered
+	}
+
+	bestA, bestB, bestDiff := 0, 1, -1
+	for a := range states {
+		for b := a + 1; b < len(states); b++ {
+			diff := 0
+			for j := range variable {
+				if states[a][j] >= 0 && states[b][j] >= 0 && states[a][j] != states[b][j] {
+					diff++
+				}
+			}
+			if diff > bestDiff {
+				bestA, bestB, bestDiff = a, b, diff
+			}
+		}
+	}
+	remaining := len(variable)
+	remaining -= pick(bestA)
+	remaining -= pick(bestB)
+
+	for remaining > 0 {
+		best, bestCover, bestFill := -1, 0, 0
+		for t := range states {
+			if chosen[t] {
+				continue
+			}
+			cover, fill := 0, 0
+			for j, st := range states[t] {
+				if st < 0 || covered[j] {
+					continue
+				}
+				if first[j] < 0 {
+					fill++
+				} else if st != first[j] {
+					cover++
+				}
+			}
+			if cover > bestCover || (cover == bestCover && fill > bestFill) {
+				best, bestCover, bestFill = t, cover, fill
+			}
+		}
+		if best < 0 {
+			break // cannot happen: every variable column has two residues somewhere
+		}
+		remaining -= pick(best)
+	}
+	return names, nil
+}
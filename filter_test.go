@@ -0,0 +1,38 @@
+package gochujang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDereplicate(t *testing.T) {
+	db := testDB(t, ">ab\nACGT\n>x\nAGGT\n>a\nACGT\n>abc\nACGT\n>yy\nAGGT\n")
+	tests := []struct {
+		keep     string
+		names    []string
+		absorbed map[string][]string
+	}{
+		{"first", []string{"ab", "x"}, map[string][]string{"ab": {"a", "abc"}, "x": {"yy"}}},
+		{"shortest-name", []string{"a", "x"}, map[string][]string{"a": {"ab", "abc"}, "x": {"yy"}}},
+		{"longest-name", []string{"abc", "yy"}, map[string][]string{"abc": {"ab", "a"}, "yy": {"x"}}},
+	}
+	for _, tt := range tests {
+		derep, absorbed, err := db.Dereplicate(tt.keep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, v := range derep.sequences {
+			names = append(names, v.name)
+		}
+		if !reflect.DeepEqual(names, tt.names) || !reflect.DeepEqual(absorbed, tt.absorbed) {
+			t.Errorf("Dereplicate(%q) = %v, %v, want %v, %v", tt.keep, names, absorbed, tt.names, tt.absorbed)
+		}
+		if derep.sequences[0].sequence != "ACGT" || derep.sequences[1].sequence != "AGGT" {
+			t.Errorf("Dereplicate(%q) kept %q, %q", tt.keep, derep.sequences[0].sequence, derep.sequences[1].sequence)
+		}
+	}
+	if _, _, err := db.Dereplicate("random"); err == nil {
+		t.Error("unknown keep rule accepted")
+	}
+}
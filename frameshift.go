@@ -0,0 +1,140 @@
+package gochujang
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	frameshiftGap     = -6  // linear gap score, per residue or codon
+	frameshiftUnknown = -4  // score for stops and X against anything
+	frameshiftPenalty = -12 // score for reading across a frameshift
+)
+
+// alignment moves into a cell of the frameshift-aware DP
+const (
+	fsCodon    = iota // codon against residue
+	fsRefGap          // residue against nothing
+	fsCodonGap        // codon against nothing
+	fsShort           // two nucleotides against a residue, one deleted
+	fsSkip1           // one nucleotide against nothing
+	fsSkip2           // two nucleotides against nothing
+	fsLong            // four nucleotides against a residue; fsLong+d drops the d-th
+)
+
+// DetectFrameshift looks for frameshifts in a CDS by aligning its codons,
+// read in frame 0, to a protein it should encode with BLOSUM62. Besides
+// matching a codon to a residue and gapping either one, the alignment may
+// read a residue from four nucleotides (one inserted) or from two (one
+// deleted), or skip one or two nucleotides, each at a frameshift penalty; a
+// shift shows up as exactly one such move, after which the codons are read
+// in the frame that matches the reference. Each is reported at its 0-based
+// position on the ungapped CDS: the extra nucleotide of an insertion, the
+// first nucleotide of the two-base codon of a deletion, the first skipped
+// nucleotide otherwise. Where the bases around an indel read as well with
+// it placed a codon earlier or later (a run of identical bases, a
+// synonymous codon straddling it), the alignment cannot tell the two apart,
+// so a reported position is usually in the codon of the indel and seldom
+// more than one codon from it. Partial codons at either end of the CDS are
+// not frameshifts.
+func DetectFrameshift(cds *Sequence, refProtein *Sequence, table int) (bool, []int, error) {
+	if cds.alphabet != Nucleotide {
+		return false, nil, fmt.Errorf("%q is not a nucleotide sequence", cds.name)
+	}
+	if refProtein.alphabet != AminoAcid {
+		return false, nil, fmt.Errorf("%q is not a protein sequence", refProtein.name)
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return false, nil, err
+	}
+	nuc := strings.ToUpper(ungapped(cds.sequence))
+	ref := strings.ToUpper(ungapped(refProtein.sequence))
+	blosum := BLOSUM62()
+	score := func(x, y byte) int {
+		if sc, ok := blosum.Score(x, y); ok {
+			return sc
+		}
+		return frameshiftUnknown
+	}
+	n, m := len(nuc), len(ref)
+	aa := make([]byte, n) // aa[i] is the residue of the codon starting at i
+	for i := 0; i+3 <= n; i++ {
+		aa[i] = code.TranslateCodon(nuc[i : i+3])
+	}
+
+	const unreachable = -1 << 30
+	dp := make([][]int, n+1)
+	move := make([][]int8, n+1)
+	for i := range dp {
+		dp[i] = make([]int, m+1)
+		move[i] = make([]int8, m+1)
+	}
+	for i := 0; i <= n; i++ {
+		for j := 0; j <= m; j++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+			best, how := unreachable, int8(-1)
+			try := func(from, sc int, mv int8) {
+				if from > unreachable && from+sc > best {
+					best, how = from+sc, mv
+				}
+			}
+			if i >= 3 && j >= 1 {
+				try(dp[i-3][j-1], score(aa[i-3], ref[j-1]), fsCodon)
+			}
+			if j >= 1 {
+				try(dp[i][j-1], frameshiftGap, fsRefGap)
+			}
+			if i >= 3 {
+				try(dp[i-3][j], frameshiftGap, fsCodonGap)
+			}
+			if i >= 2 && j >= 1 {
+				try(dp[i-2][j-1], frameshiftPenalty, fsShort)
+			}
+			if i >= 1 {
+				try(dp[i-1][j], frameshiftPenalty, fsSkip1)
+			}
+			if i >= 2 {
+				try(dp[i-2][j], frameshiftPenalty, fsSkip2)
+			}
+			if i >= 4 && j >= 1 {
+				four := nuc[i-4 : i]
+				for d := 0; d < 4; d++ {
+					codon := four[:d] + four[d+1:]
+					try(dp[i-4][j-1], frameshiftPenalty+score(code.TranslateCodon(codon), ref[j-1]), fsLong+int8(d))
+				}
+			}
+			dp[i][j], move[i][j] = best, how
+		}
+	}
+
+	var shifts []int
+	for i, j := n, m; i > 0 || j > 0; {
+		switch mv := move[i][j]; {
+		case mv == fsCodon:
+			i, j = i-3, j-1
+		case mv == fsRefGap:
+			j--
+		case mv == fsCodonGap:
+			i -= 3
+		case mv == fsShort:
+			i, j = i-2, j-1
+			shifts = append(shifts, i)
+		case mv == fsSkip1 || mv == fsSkip2:
+			end := i
+			i -= int(mv-fsSkip1) + 1
+			if i > 0 && end < n { // not a partial codon at either end
+				shifts = append(shifts, i)
+			}
+		default:
+			i, j = i-4, j-1
+			shifts = append(shifts, i+int(mv-fsLong))
+		}
+	}
+	for a, b := 0, len(shifts)-1; a < b; a, b = a+1, b-1 {
+		shifts[a], shifts[b] = shifts[b], shifts[a]
+	}
+	return len(shifts) > 0, shifts, nil
+}
@@ -0,0 +1,43 @@
+package gochujang
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectFrameshift(t *testing.T) {
+	// the indels below leave no other codon that reads as well, so each can
+	// only be placed in the codon it hits
+	nuc := strings.Repeat("ATGTGGTGTCATTATTTTAAACCC", 4)
+	ref := &Sequence{alphabet: AminoAcid, name: "ref", sequence: strings.Repeat("MWCHYFKP", 4)}
+	tests := []struct {
+		name string
+		cds  string
+		want []int
+	}{
+		{"intact", nuc, nil},
+		{"deletion", nuc[:40] + nuc[41:], []int{39}},
+		{"insertion", nuc[:50] + "C" + nuc[50:], []int{50}},
+		{"two deletions", nuc[:16] + nuc[17:73] + nuc[74:], []int{15, 71}},
+	}
+	for _, tt := range tests {
+		cds := &Sequence{alphabet: Nucleotide, name: tt.name, sequence: tt.cds}
+		found, shifts, err := DetectFrameshift(cds, ref, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != (len(tt.want) > 0) || len(shifts) != len(tt.want) {
+			t.Errorf("%s: found = %v, shifts = %v, want %v", tt.name, found, shifts, tt.want)
+			continue
+		}
+		for i := range shifts {
+			if shifts[i] != tt.want[i] {
+				t.Errorf("%s: shifts = %v, want %v", tt.name, shifts, tt.want)
+			}
+		}
+	}
+
+	if _, _, err := DetectFrameshift(ref, ref, 1); err == nil {
+		t.Error("protein accepted as the CDS")
+	}
+}
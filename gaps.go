@@ -0,0 +1,173 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OverallGapFraction returns the fraction of all cells in the alignment
+// matrix that are gaps.
+func (s SequenceDB) OverallGapFraction() (float64, error) {
+	if !s.aligned {
+		return 0, ErrNotAligned
+	}
+	cells := len(s.sequences) * s.length
+	if cells == 0 {
+		return 0, errors.New("alignment is empty")
+	}
+	gaps := 0
+	for _, v := range s.sequences {
+		for i := 0; i < len(v.sequence); i++ {
+			if isGap(v.sequence[i]) {
+				gaps++
+			}
+		}
+	}
+	return float64(gaps) / float64(cells), nil
+}
+
+// TrimEnds clips columns off both ends of the alignment up to the first and
+// last columns whose fraction of non-gap residues reaches minOccupancy.
+// Gappy columns in between are kept.
+func (s SequenceDB) TrimEnds(minOccupancy float64) (SequenceDB, error) {
+	if !s.aligned {
+		return SequenceDB{}, ErrNotAligned
+	}
+	if minOccupancy < 0 || minOccupancy > 1 {
+		return SequenceDB{}, errors.New("minimum occupancy must be between 0 and 1")
+	}
+	occupied := func(col int) bool {
+		n := 0
+		for _, v := range s.sequences {
+			if !isGap(v.sequence[col]) {
+				n++
+			}
+		}
+		return float64(n) >= minOccupancy*float64(len(s.sequences))
+	}
+	start, end := 0, s.length
+	for start < end && !occupied(start) {
+		start++
+	}
+	for end > start && !occupied(end-1) {
+		end--
+	}
+	if start == end {
+		return SequenceDB{}, errors.New("no column reaches the minimum occupancy")
+	}
+	cols := make([]int, 0, end-start)
+	for col := start; col < end; col++ {
+		cols = append(cols, col)
+	}
+	return s.selectColumns(cols), nil
+}
+
+// GapPatternSimilarity is the fraction of positions at which two aligned
+// sequences agree on whether there is a gap, regardless of the residues.
+func GapPatternSimilarity(a, b *Sequence) (float64, error) {
+	if len(a.sequence) != len(b.sequence) {
+		return 0, fmt.Errorf("sequences %q and %q are not the same length", a.name, b.name)
+	}
+	if len(a.sequence) == 0 {
+		return 0, errors.New("sequences are empty")
+	}
+	agree := 0
+	for i := 0; i < len(a.sequence); i++ {
+		if isGap(a.sequence[i]) == isGap(b.sequence[i]) {
+			agree++
+		}
+	}
+	return float64(agree) / float64(len(a.sequence)), nil
+}
+
+// GapRuns returns the [start, end) column ranges of every run of gaps in
+// the sequence.
+func (s Sequence) GapRuns() [][2]int {
+	var runs [][2]int
+	start := -1
+	for i := 0; i <= len(s.sequence); i++ {
+		gap := i < len(s.sequence) && isGap(s.sequence[i])
+		if gap && start < 0 {
+			start = i
+		} else if !gap && start >= 0 {
+			runs = append(runs, [2]int{start, i})
+			start = -1
+		}
+	}
+	return runs
+}
+
+// GapStatistics counts, over all sequences, the gap runs (openings) and the
+// gap positions past the first of each run (extensions).
+func (s SequenceDB) GapStatistics() (opens, extends int, err error) {
+	if !s.aligned {
+		return 0, 0, ErrNotAligned
+	}
+	for _, v := range s.sequences {
+		for _, run := range v.GapRuns() {
+			opens++
+			extends += run[1] - run[0] - 1
+		}
+	}
+	return opens, extends, nil
+}
+
+// EffectiveLengths returns the number of non-gap characters of each aligned
+// sequence, ambiguity codes included.
+func (s SequenceDB) EffectiveLengths() (map[string]int, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	lengths := make(map[string]int, len(s.sequences))
+	for _, v := range s.sequences {
+		lengths[v.name] = len(ungapped(v.sequence))
+	}
+	return lengths, nil
+}
+
+// LongestUngappedBlock returns, per sequence, the [start, end) columns of its
+// longest run without gaps; the first one wins ties. An all-gap sequence
+// gets [0, 0).
+func (s SequenceDB) LongestUngappedBlock() (map[string][2]int, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	blocks := make(map[string][2]int, len(s.sequences))
+	for _, v := range s.sequences {
+		var best [2]int
+		start := 0
+		for i := 0; i <= len(v.sequence); i++ {
+			if i < len(v.sequence) && !isGap(v.sequence[i]) {
+				continue
+			}
+			if i-start > best[1]-best[0] {
+				best = [2]int{start, i}
+			}
+			start = i + 1
+		}
+		blocks[v.name] = best
+	}
+	return blocks, nil
+}
+
+// CompletenessPerSequence returns, per sequence, the fraction of positions
+// holding a residue of the DB's alphabet; gaps, N, X and other ambiguity
+// codes count as missing. Empty sequences get 0.
+func (s SequenceDB) CompletenessPerSequence() map[string]float64 {
+	index := stateIndex(s.alphabet)
+	completeness := make(map[string]float64, len(s.sequences))
+	for _, v := range s.sequences {
+		if len(v.sequence) == 0 {
+			completeness[v.name] = 0
+			continue
+		}
+		n := 0
+		for i := 0; i < len(v.sequence); i++ {
+			if _, ok := index[v.sequence[i]]; ok {
+				n++
+			}
+		}
+		completeness[v.name] = float64(n) / float64(len(v.sequence))
+	}
+	return completeness
+}
@@ -0,0 +1,62 @@
+package gochujang
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// testDB builds a refreshed DB from FASTA text.
+func testDB(t *testing.T, fasta string) SequenceDB {
+	t.Helper()
+	seqs, err := readFasta(strings.NewReader(fasta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := SequenceDB{sequences: seqs}
+	if err := db.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestOverallGapFraction(t *testing.T) {
+	tests := []struct {
+		fasta string
+		want  float64
+	}{
+		{">a\nACGT\n>b\nACGT\n", 0},
+		{">a\nACGT\n>b\nAC--\n>c\n----\n", 0.5}, // 6 of 12 cells
+		{">a\nAC-T\n>b\n-CGT\n>c\nACGT\n", 2.0 / 12},
+		{">a\n---\n>b\n---\n", 1},
+	}
+	for _, tt := range tests {
+		got, err := testDB(t, tt.fasta).OverallGapFraction()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("OverallGapFraction(%q) = %g, want %g", tt.fasta, got, tt.want)
+		}
+	}
+
+	if _, err := testDB(t, ">a\nACGT\n>b\nACG\n").OverallGapFraction(); !errors.Is(err, ErrNotAligned) {
+		t.Errorf("unaligned DB gave error %v, want ErrNotAligned", err)
+	}
+	if _, err := (SequenceDB{}).OverallGapFraction(); err == nil {
+		t.Error("empty DB should give an error")
+	}
+}
+
+func TestGapStatistics(t *testing.T) {
+	db := testDB(t, ">a\nA--CG---T\n>b\nACGTACGTA\n>c\n-CGTACGT-\n")
+	opens, extends, err := db.GapStatistics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a: runs of 2 and 3, c: two runs of 1
+	if opens != 4 || extends != 3 {
+		t.Errorf("GapStatistics = %d opens, %d extends, want 4, 3", opens, extends)
+	}
+}
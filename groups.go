@@ -0,0 +1,137 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+)
+
+// fixedState returns the one state held by seqs at col, ignoring gaps and
+// ambiguity codes; ok is false when they hold none or more than one.
+func fixedState(seqs []*Sequence, col int, index map[byte]int) (state int, ok bool) {
+	state = -1
+	for _, v := range seqs {
+		st, isState := index[v.sequence[col]]
+		if !isState {
+			continue
+		}
+		if state >= 0 && st != state {
+			return -1, false
+		}
+		state = st
+	}
+	return state, state >= 0
+}
+
+// disjointGroups fails when a taxon is named in both groups.
+func disjointGroups(groupA, groupB []string) error {
+	inA := make(map[string]bool, len(groupA))
+	for _, name := range groupA {
+		inA[name] = true
+	}
+	for _, name := range groupB {
+		if inA[name] {
+			return fmt.Errorf("taxon %q is in both groups", name)
+		}
+	}
+	return nil
+}
+
+// GroupDiagnosticSites returns the columns at which the taxa of groupA are
+// fixed for one residue and the taxa of groupB for another. Gaps and
+// ambiguity codes are ignored, but each group needs at least one residue.
+// The groups may not share a taxon.
+func (s SequenceDB) GroupDiagnosticSites(groupA, groupB []string) ([]int, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	if len(groupA) == 0 || len(groupB) == 0 {
+		return nil, errors.New("both taxon groups need at least one name")
+	}
+	if err := disjointGroups(groupA, groupB); err != nil {
+		return nil, err
+	}
+	a, err := s.getSequences(groupA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.getSequences(groupB)
+	if err != nil {
+		return nil, err
+	}
+	index := stateIndex(s.alphabet)
+	var sites []int
+	for col := 0; col < s.length; col++ {
+		sa, okA := fixedState(a, col, index)
+		sb, okB := fixedState(b, col, index)
+		if okA && okB && sa != sb {
+			sites = append(sites, col)
+		}
+	}
+	return sites, nil
+}
+
+// GroupBF computes the DB-level base frequencies separately for each named
+// group of taxa.
+func (s SequenceDB) GroupBF(groups map[string][]string) (map[string][]float64, error) {
+	bf := make(map[string][]float64)
+	for group, names := range groups {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("group %q has no taxa", group)
+		}
+		seqs, err := s.getSequences(names)
+		if err != nil {
+			return nil, fmt.Errorf("group %q: %w", group, err)
+		}
+		sub := SequenceDB{alphabet: s.alphabet, sequences: seqs}
+		sub.CalcBF()
+		bf[group] = sub.BF
+	}
+	return bf, nil
+}
+
+// SplitSupport counts the columns supporting the split groupA | groupB (the
+// diagnostic sites of GroupDiagnosticSites) and the columns conflicting
+// with it: those with two or more states found on both sides, which makes
+// them parsimony-informative and means no tree with that split fits them
+// without homoplasy. Gaps and ambiguity codes are ignored, and the groups
+// may not share a taxon.
+func (s SequenceDB) SplitSupport(groupA, groupB []string) (support, conflict int, err error) {
+	sites, err := s.GroupDiagnosticSites(groupA, groupB)
+	if err != nil {
+		return 0, 0, err
+	}
+	a, err := s.getSequences(groupA)
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := s.getSequences(groupB)
+	if err != nil {
+		return 0, 0, err
+	}
+	index := stateIndex(s.alphabet)
+	nstates := len(GetStates(s.alphabet))
+	for col := 0; col < s.length; col++ {
+		inA := make([]int, nstates)
+		inB := make([]int, nstates)
+		for _, v := range a {
+			if st, ok := index[v.sequence[col]]; ok {
+				inA[st]++
+			}
+		}
+		for _, v := range b {
+			if st, ok := index[v.sequence[col]]; ok {
+				inB[st]++
+			}
+		}
+		shared := 0
+		for st := range inA {
+			if inA[st] > 0 && inB[st] > 0 {
+				shared++
+			}
+		}
+		if shared >= 2 {
+			conflict++
+		}
+	}
+	return len(sites), conflict, nil
+}
@@ -0,0 +1,39 @@
+package gochujang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupDiagnosticSites(t *testing.T) {
+	// column 0 is invariant, 1 splits the groups, 2 splits them apart from
+	// a gap, 3 varies within group a
+	db := testDB(t, ">a1\nAAAA\n>a2\nAA-C\n>b1\nACCA\n>b2\nACCA\n")
+	sites, err := db.GroupDiagnosticSites([]string{"a1", "a2"}, []string{"b1", "b2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(sites, want) {
+		t.Errorf("GroupDiagnosticSites = %v, want %v", sites, want)
+	}
+	if _, err := db.GroupDiagnosticSites([]string{"a1", "a2"}, []string{"a2", "b1"}); err == nil {
+		t.Error("taxon in both groups accepted")
+	}
+}
+
+func TestSplitSupport(t *testing.T) {
+	// columns 0 and 1 support a | b, column 2 has A and C on both sides,
+	// column 3 only has a singleton and column 4 A and C within group a only
+	db := testDB(t, ">a1\nAAAAA\n>a2\nAAC-C\n>b1\nCCAAA\n>b2\nCCCGA\n")
+	groupA, groupB := []string{"a1", "a2"}, []string{"b1", "b2"}
+	support, conflict, err := db.SplitSupport(groupA, groupB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if support != 2 || conflict != 1 {
+		t.Errorf("SplitSupport = %d, %d, want 2, 1", support, conflict)
+	}
+	if _, _, err := db.SplitSupport(groupA, []string{"b1", "a1"}); err == nil {
+		t.Error("taxon in both groups accepted")
+	}
+}
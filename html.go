@@ -0,0 +1,95 @@
+package gochujang
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+)
+
+// aaProperty classifies an amino acid by its side chain, "" if unknown.
+func aaProperty(b byte) string {
+	switch upperByte(b) {
+	case 'A', 'V', 'L', 'I', 'M':
+		return "hydrophobic"
+	case 'F', 'W', 'Y':
+		return "aromatic"
+	case 'K', 'R', 'H':
+		return "positive"
+	case 'D', 'E':
+		return "negative"
+	case 'S', 'T', 'N', 'Q':
+		return "polar"
+	case 'G', 'P':
+		return "special"
+	case 'C':
+		return "cysteine"
+	}
+	return ""
+}
+
+const htmlStyle = `table{border-collapse:collapse;font-family:monospace}
+td,th{padding:0 1px;text-align:center}
+td.name{text-align:left;padding-right:1em;white-space:nowrap}
+th{font-weight:normal;font-size:smaller;color:#888}
+.nA{background:#5ee85e}.nC{background:#6ea7f5}.nG{background:#f5c26e}.nT,.nU{background:#f57272}
+.hydrophobic{background:#80a0f0}.aromatic{background:#15a4a4}.positive{background:#f01505}
+.negative{background:#c048c0}.polar{background:#15c015}.special{background:#f09048}.cysteine{background:#f08080}
+`
+
+// WriteHTML writes the alignment as a standalone HTML page: one row per
+// sequence with its name first and one cell per column. colorScheme is
+// "nucleotide" (by base), "property" (amino acids by side chain), "none",
+// or "" to pick nucleotide or property from the alphabet.
+func (s SequenceDB) WriteHTML(w io.Writer, colorScheme string) error {
+	if colorScheme == "" {
+		colorScheme = "property"
+		if s.alphabet == Nucleotide {
+			colorScheme = "nucleotide"
+		}
+	}
+	var class func(b byte) string
+	switch colorScheme {
+	case "nucleotide":
+		class = func(b byte) string {
+			switch b = upperByte(b); b {
+			case 'A', 'C', 'G', 'T', 'U':
+				return "n" + string(b)
+			}
+			return ""
+		}
+	case "property":
+		class = aaProperty
+	case "none":
+		class = func(byte) string { return "" }
+	default:
+		return fmt.Errorf("unknown color scheme %q", colorScheme)
+	}
+
+	width := 0
+	for _, v := range s.sequences {
+		if len(v.sequence) > width {
+			width = len(v.sequence)
+		}
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>alignment</title>\n<style>\n%s</style>\n</head>\n<body>\n<table>\n<tr><th></th>", htmlStyle)
+	for col := 1; col <= width; col++ {
+		fmt.Fprintf(bw, "<th>%d</th>", col)
+	}
+	bw.WriteString("</tr>\n")
+	for _, v := range s.sequences {
+		fmt.Fprintf(bw, "<tr><td class=\"name\">%s</td>", html.EscapeString(v.name))
+		for i := 0; i < len(v.sequence); i++ {
+			residue := html.EscapeString(string(v.sequence[i]))
+			if c := class(v.sequence[i]); c != "" {
+				fmt.Fprintf(bw, "<td class=\"%s\">%s</td>", c, residue)
+			} else {
+				fmt.Fprintf(bw, "<td>%s</td>", residue)
+			}
+		}
+		bw.WriteString("</tr>\n")
+	}
+	bw.WriteString("</table>\n</body>\n</html>\n")
+	return bw.Flush()
+}
@@ -0,0 +1,152 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// identityCounts counts the columns in [start, end) where neither sequence
+// has a gap and how many of those hold the same residue, ignoring case.
+func identityCounts(a, b *Sequence, start, end int) (same, compared int) {
+	for i := start; i < end; i++ {
+		x, y := a.sequence[i], b.sequence[i]
+		if isGap(x) || isGap(y) {
+			continue
+		}
+		compared++
+		if upperByte(x) == upperByte(y) {
+			same++
+		}
+	}
+	return same, compared
+}
+
+// PercentIdentity is the percentage (0-100) of identical residues over the
+// columns where neither aligned sequence has a gap.
+func PercentIdentity(a, b *Sequence) (float64, error) {
+	if len(a.sequence) != len(b.sequence) {
+		return 0, fmt.Errorf("sequences %q and %q are not the same length", a.name, b.name)
+	}
+	same, compared := identityCounts(a, b, 0, len(a.sequence))
+	if compared == 0 {
+		return 0, errNoOverlap
+	}
+	return 100 * float64(same) / float64(compared), nil
+}
+
+// SlidingIdentity returns PercentIdentity in windows of the given width
+// moved along the alignment by step, with the 0-based start column of each
+// window. Windows without any comparable column get NaN.
+func SlidingIdentity(a, b *Sequence, window, step int) ([]float64, []int, error) {
+	if len(a.sequence) != len(b.sequence) {
+		return nil, nil, fmt.Errorf("sequences %q and %q are not the same length", a.name, b.name)
+	}
+	if window < 1 || step < 1 {
+		return nil, nil, errors.New("window and step must be at least 1")
+	}
+	if window > len(a.sequence) {
+		return nil, nil, fmt.Errorf("window of %d is longer than the %d columns", window, len(a.sequence))
+	}
+	var identity []float64
+	var starts []int
+	for start := 0; start+window <= len(a.sequence); start += step {
+		same, compared := identityCounts(a, b, start, start+window)
+		id := math.NaN()
+		if compared > 0 {
+			id = 100 * float64(same) / float64(compared)
+		}
+		identity = append(identity, id)
+		starts = append(starts, start)
+	}
+	return identity, starts, nil
+}
+
+// RecombinationSignal slides a window (a tenth of the alignment, at least 20
+// columns) along the alignment and finds, in each, the sequence most
+// identical to refName. It returns the centre columns of the windows where
+// that best match changes, as candidate breakpoints. Ties keep the previous
+// best match so noise between equally close sequences is not reported.
+func (s SequenceDB) RecombinationSignal(refName string) ([]int, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	ref, err := s.getSequence(refName)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.sequences) < 3 {
+		return nil, errors.New("need the reference and at least two other sequences")
+	}
+	window := s.length / 10
+	if window < 20 {
+		window = 20
+	}
+	if window > s.length {
+		window = s.length
+	}
+	step := window / 4
+	if step < 1 {
+		step = 1
+	}
+
+	var breakpoints []int
+	var best *Sequence
+	for start := 0; start+window <= s.length; start += step {
+		prev := best
+		bestID := -1.0
+		if prev != nil {
+			if same, compared := identityCounts(ref, prev, start, start+window); compared > 0 {
+				bestID = float64(same) / float64(compared)
+			}
+		}
+		for _, v := range s.sequences {
+			if v == ref || v == prev {
+				continue
+			}
+			same, compared := identityCounts(ref, v, start, start+window)
+			if compared == 0 {
+				continue
+			}
+			if id := float64(same) / float64(compared); id > bestID {
+				best, bestID = v, id
+			}
+		}
+		if prev != nil && best != prev {
+			breakpoints = append(breakpoints, start+window/2)
+		}
+	}
+	return breakpoints, nil
+}
+
+// MeanPairwiseIdentity is the percent identity of all pairs of sequences
+// taken together, accumulated column by column rather than pair by pair: the
+// identical pairs summed over columns divided by the pairs compared, with
+// the same gap and case handling as PercentIdentity. Pairs therefore weigh
+// in by how many columns they share, and without gaps this is exactly the
+// mean of the pairwise identities.
+func (s SequenceDB) MeanPairwiseIdentity() (float64, error) {
+	if !s.aligned {
+		return 0, ErrNotAligned
+	}
+	var same, compared float64
+	var counts [256]int
+	for i := 0; i < s.length; i++ {
+		counts = [256]int{}
+		n := 0
+		for _, v := range s.sequences {
+			if c := v.sequence[i]; !isGap(c) {
+				counts[upperByte(c)]++
+				n++
+			}
+		}
+		compared += float64(n) * float64(n-1) / 2
+		for _, c := range counts {
+			same += float64(c) * float64(c-1) / 2
+		}
+	}
+	if compared == 0 {
+		return 0, errNoOverlap
+	}
+	return 100 * same / compared, nil
+}
@@ -0,0 +1,125 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var iupacBases = map[byte]string{
+	'A': "A", 'C': "C", 'G': "G", 'T': "T", 'U': "T",
+	'R': "AG", 'Y': "CT", 'S': "CG", 'W': "AT", 'K': "GT", 'M': "AC",
+	'B': "CGT", 'D': "AGT", 'H': "ACT", 'V': "ACG", 'N': "ACGT",
+}
+
+const maxExpansions = 10000 // guard against enumerating huge degenerate sets
+
+// iupacExpansions gives the concrete bases for every position of a
+// nucleotide sequence written with IUPAC codes; gaps expand to themselves.
+func iupacExpansions(residues string) ([]string, error) {
+	sets := make([]string, len(residues))
+	for i := 0; i < len(residues); i++ {
+		c := residues[i]
+		if isGap(c) {
+			sets[i] = string(c)
+			continue
+		}
+		bases, ok := iupacBases[strings.ToUpper(string(c))[0]]
+		if !ok {
+			return nil, fmt.Errorf("%q is not an IUPAC nucleotide code", c)
+		}
+		sets[i] = bases
+	}
+	return sets, nil
+}
+
+// Degeneracy is the number of concrete sequences an IUPAC-coded nucleotide
+// sequence stands for, e.g. 4 per N and 2 per R. Gaps count once.
+func (s Sequence) Degeneracy() (int, error) {
+	sets, err := iupacExpansions(s.sequence)
+	if err != nil {
+		return 0, err
+	}
+	deg := 1
+	for _, set := range sets {
+		if deg > math.MaxInt/4 {
+			return 0, fmt.Errorf("degeneracy of %q overflows", s.name)
+		}
+		deg *= len(set)
+	}
+	return deg, nil
+}
+
+// ExpandAmbiguous enumerates every concrete sequence an IUPAC-coded
+// sequence stands for, named name_1, name_2, ... It refuses to produce more
+// than 10000 sequences.
+func (s Sequence) ExpandAmbiguous() ([]*Sequence, error) {
+	deg, err := s.Degeneracy()
+	if err != nil {
+		return nil, err
+	}
+	if deg > maxExpansions {
+		return nil, fmt.Errorf("%q expands to %d sequences, more than the limit of %d", s.name, deg, maxExpansions)
+	}
+	sets, _ := iupacExpansions(s.sequence)
+	expanded := []string{""}
+	for _, set := range sets {
+		var next []string
+		for _, prefix := range expanded {
+			for i := 0; i < len(set); i++ {
+				next = append(next, prefix+set[i:i+1])
+			}
+		}
+		expanded = next
+	}
+	seqs := make([]*Sequence, len(expanded))
+	for i, residues := range expanded {
+		seqs[i] = NewSequence()
+		seqs[i].name = s.name + "_" + strconv.Itoa(i+1)
+		seqs[i].sequence = residues
+		seqs[i].alphabet = Nucleotide
+		seqs[i].CalcBF()
+	}
+	return seqs, nil
+}
+
+var iupacComplement = map[byte]byte{
+	'A': 'T', 'C': 'G', 'G': 'C', 'T': 'A', 'U': 'A',
+	'R': 'Y', 'Y': 'R', 'S': 'S', 'W': 'W', 'K': 'M', 'M': 'K',
+	'B': 'V', 'V': 'B', 'D': 'H', 'H': 'D', 'N': 'N',
+}
+
+// reverseComplement reverse-complements IUPAC-coded residues, keeping case;
+// gaps and unknown characters are kept as they are.
+func reverseComplement(residues string) string {
+	rc := make([]byte, len(residues))
+	for i := 0; i < len(residues); i++ {
+		c := residues[i]
+		comp, ok := iupacComplement[c&^0x20] // uppercase for the lookup
+		if ok {
+			comp |= c & 0x20 // and back to the original case
+		} else {
+			comp = c
+		}
+		rc[len(residues)-1-i] = comp
+	}
+	return string(rc)
+}
+
+// ReverseComplement reverse-complements every sequence. Reversing whole rows
+// also reverses the column order, so an alignment stays aligned.
+func (s SequenceDB) ReverseComplement() (SequenceDB, error) {
+	if s.alphabet != Nucleotide {
+		return SequenceDB{}, errors.New("reverse complement needs nucleotide sequences")
+	}
+	var seqs []*Sequence
+	for _, v := range s.sequences {
+		seq := NewSequence()
+		seq.name = v.name
+		seq.sequence = reverseComplement(v.sequence)
+		seqs = append(seqs, seq)
+	}
+	return s.withSequences(seqs), nil
+}
@@ -0,0 +1,192 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+// eachKmer calls fn on every k-mer of the ungapped, uppercased residues,
+// skipping windows that contain anything outside the alphabet (N, X, ...).
+func eachKmer(residues string, k int, alphabet DataType, fn func(kmer string)) error {
+	if k < 1 {
+		return errors.New("k must be at least 1")
+	}
+	index := stateIndex(alphabet)
+	residues = strings.ToUpper(ungapped(residues))
+	lastBad := -1
+	for i := 0; i < len(residues); i++ {
+		if _, ok := index[residues[i]]; !ok {
+			lastBad = i
+		}
+		if i+1 >= k && i-k >= lastBad {
+			fn(residues[i+1-k : i+1])
+		}
+	}
+	return nil
+}
+
+// KmerCounts counts the k-mers of the ungapped sequence. Windows spanning an
+// ambiguity code are skipped.
+func (s Sequence) KmerCounts(k int) (map[string]int, error) {
+	counts := make(map[string]int)
+	err := eachKmer(s.sequence, k, s.alphabet, func(kmer string) {
+		counts[kmer]++
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// UniqueKmerCount returns the number of distinct k-mers in the sequence.
+func (s Sequence) UniqueKmerCount(k int) (int, error) {
+	counts, err := s.KmerCounts(k)
+	if err != nil {
+		return 0, err
+	}
+	return len(counts), nil
+}
+
+const hllPrecision = 14 // 2^14 registers, about 1% standard error
+
+// UniqueKmerCountApprox estimates the number of distinct k-mers with
+// HyperLogLog, using a fixed 16 KB of registers however long the sequence.
+func (s Sequence) UniqueKmerCountApprox(k int) (int, error) {
+	m := 1 << hllPrecision
+	registers := make([]uint8, m)
+	err := eachKmer(s.sequence, k, s.alphabet, func(kmer string) {
+		h := fnv.New64a()
+		h.Write([]byte(kmer))
+		x := mix64(h.Sum64())
+		idx := x >> (64 - hllPrecision)
+		rho := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+		if rho > registers[idx] {
+			registers[idx] = rho
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	zeros := 0
+	for _, r := range registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/float64(m))
+	estimate := alpha * float64(m) * float64(m) / sum
+	if estimate <= 2.5*float64(m) && zeros > 0 { // small range correction
+		estimate = float64(m) * math.Log(float64(m)/float64(zeros))
+	}
+	return int(math.Round(estimate)), nil
+}
+
+func mix64(x uint64) uint64 { // murmur3 finalizer, spreads FNV's weak high bits
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// OrientToReference flips every sequence whose reverse complement shares
+// more distinct k-mers with the reference than the sequence itself does. It
+// returns the reoriented copy of the DB and the names that were flipped.
+func (s SequenceDB) OrientToReference(referenceName string, k int) (SequenceDB, []string, error) {
+	if s.alphabet != Nucleotide {
+		return SequenceDB{}, nil, errors.New("orientation needs nucleotide sequences")
+	}
+	ref, err := s.getSequence(referenceName)
+	if err != nil {
+		return SequenceDB{}, nil, err
+	}
+	refKmers, err := ref.KmerCounts(k)
+	if err != nil {
+		return SequenceDB{}, nil, err
+	}
+	shared := func(residues string) int {
+		n := 0
+		seen := make(map[string]bool)
+		eachKmer(residues, k, Nucleotide, func(kmer string) {
+			if refKmers[kmer] > 0 && !seen[kmer] {
+				seen[kmer] = true
+				n++
+			}
+		})
+		return n
+	}
+
+	var seqs []*Sequence
+	var flipped []string
+	for _, v := range s.sequences {
+		seq := NewSequence()
+		seq.name = v.name
+		seq.sequence = v.sequence
+		if v != ref {
+			if rc := reverseComplement(v.sequence); shared(rc) > shared(v.sequence) {
+				seq.sequence = rc
+				flipped = append(flipped, v.name)
+			}
+		}
+		seqs = append(seqs, seq)
+	}
+	return s.withSequences(seqs), flipped, nil
+}
+
+// EstimateGenomeSize estimates genome size from the canonical k-mer spectrum
+// of a read set. The histogram of k-mer abundances is walked past the
+// low-abundance error peak to its first valley, the most frequent abundance
+// beyond that is taken as the k-mer coverage, and the size is the number of
+// k-mers from the valley on divided by that coverage.
+func EstimateGenomeSize(records []FastqRecord, k int) (size int64, coverage float64, err error) {
+	counts := make(map[string]int)
+	for _, r := range records {
+		err := eachKmer(r.Sequence, k, Nucleotide, func(kmer string) {
+			if rc := reverseComplement(kmer); rc < kmer {
+				kmer = rc
+			}
+			counts[kmer]++
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(counts) == 0 {
+		return 0, 0, errors.New("reads contain no k-mers")
+	}
+	maxAbundance := 0
+	for _, n := range counts {
+		if n > maxAbundance {
+			maxAbundance = n
+		}
+	}
+	hist := make([]int64, maxAbundance+2) // hist[a] k-mers seen a times, padded past the end
+	for _, n := range counts {
+		hist[n]++
+	}
+
+	valley := 1
+	for valley <= maxAbundance && hist[valley+1] <= hist[valley] {
+		valley++
+	}
+	if valley > maxAbundance {
+		return 0, 0, fmt.Errorf("k-mer spectrum has no coverage peak at k=%d", k)
+	}
+	peak := valley
+	var total int64
+	for a := valley; a <= maxAbundance; a++ {
+		if hist[a] > hist[peak] {
+			peak = a
+		}
+		total += int64(a) * hist[a]
+	}
+	return int64(math.Round(float64(total) / float64(peak))), float64(peak), nil
+}
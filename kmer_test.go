@@ -0,0 +1,48 @@
+package gochujang
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestKmerCounts(t *testing.T) {
+	s := &Sequence{name: "s", sequence: "acg-TAcgN", alphabet: Nucleotide}
+	counts, err := s.KmerCounts(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ungapped ACGTACGN: AC CG GT TA AC CG, GN skipped
+	want := map[string]int{"AC": 2, "CG": 2, "GT": 1, "TA": 1}
+	if len(counts) != len(want) {
+		t.Fatalf("KmerCounts = %v, want %v", counts, want)
+	}
+	for k, n := range want {
+		if counts[k] != n {
+			t.Errorf("count of %s = %d, want %d", k, counts[k], n)
+		}
+	}
+}
+
+func TestUniqueKmerCountApprox(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	residues := make([]byte, 100000)
+	for i := range residues {
+		residues[i] = "ACGT"[rng.Intn(4)]
+	}
+	s := &Sequence{name: "s", sequence: string(residues), alphabet: Nucleotide}
+	for _, k := range []int{5, 21} {
+		exact, err := s.UniqueKmerCount(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		approx, err := s.UniqueKmerCountApprox(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// about 1% standard error with 2^14 registers; allow 4 of them
+		if rel := math.Abs(float64(approx-exact)) / float64(exact); rel > 0.04 {
+			t.Errorf("k=%d: approximate count %d is %.1f%% off the exact %d", k, approx, 100*rel, exact)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package gochujang
+
+import "fmt"
+
+// SubstitutionMatrix scores pairs of residues of one alphabet; rows and
+// columns follow GetStates order.
+type SubstitutionMatrix struct {
+	alphabet DataType
+	index    map[byte]int
+	scores   [][]int
+}
+
+func NewSubstitutionMatrix(alphabet DataType, scores [][]int) (*SubstitutionMatrix, error) {
+	n := len(GetStates(alphabet))
+	if n == 0 {
+		return nil, fmt.Errorf("no states defined for alphabet %q", alphabet)
+	}
+	if len(scores) != n {
+		return nil, fmt.Errorf("matrix has %d rows, alphabet %q has %d states", len(scores), alphabet, n)
+	}
+	for i, row := range scores {
+		if len(row) != n {
+			return nil, fmt.Errorf("matrix row %d has %d scores, expected %d", i, len(row), n)
+		}
+	}
+	return &SubstitutionMatrix{alphabet: alphabet, index: stateIndex(alphabet), scores: scores}, nil
+}
+
+// Score looks up a pair of residues, ok being false when either is not a
+// state of the matrix's alphabet.
+func (m *SubstitutionMatrix) Score(x, y byte) (score int, ok bool) {
+	i, okx := m.index[x]
+	j, oky := m.index[y]
+	if !okx || !oky {
+		return 0, false
+	}
+	return m.scores[i][j], true
+}
+
+// NucleotideMatrix scores identical bases with match and others with mismatch.
+func NucleotideMatrix(match, mismatch int) *SubstitutionMatrix {
+	n := len(GetStates(Nucleotide))
+	scores := make([][]int, n)
+	for i := range scores {
+		scores[i] = make([]int, n)
+		for j := range scores[i] {
+			scores[i][j] = mismatch
+		}
+		scores[i][i] = match
+	}
+	m, _ := NewSubstitutionMatrix(Nucleotide, scores)
+	return m
+}
+
+func BLOSUM62() *SubstitutionMatrix {
+	m, _ := NewSubstitutionMatrix(AminoAcid, [][]int{
+		// A   R   N   D   C   Q   E   G   H   I   L   K   M   F   P   S   T   W   Y   V
+		{4, -1, -2, -2, 0, -1, -1, 0, -2, -1, -1, -1, -1, -2, -1, 1, 0, -3, -2, 0},
+		{-1, 5, 0, -2, -3, 1, 0, -2, 0, -3, -2, 2, -1, -3, -2, -1, -1, -3, -2, -3},
+		{-2, 0, 6, 1, -3, 0, 0, 0, 1, -3, -3, 0, -2, -3, -2, 1, 0, -4, -2, -3},
+		{-2, -2, 1, 6, -3, 0, 2, -1, -1, -3, -4, -1, -3, -3, -1, 0, -1, -4, -3, -3},
+		{0, -3, -3, -3, 9, -3, -4, -3, -3, -1, -1, -3, -1, -2, -3, -1, -1, -2, -2, -1},
+		{-1, 1, 0, 0, -3, 5, 2, -2, 0, -3, -2, 1, 0, -3, -1, 0, -1, -2, -1, -2},
+		{-1, 0, 0, 2, -4, 2, 5, -2, 0, -3, -3, 1, -2, -3, -1, 0, -1, -3, -2, -2},
+		{0, -2, 0, -1, -3, -2, -2, 6, -2, -4, -4, -2, -3, -3, -2, 0, -2, -2, -3, -3},
+		{-2, 0, 1, -1, -3, 0, 0, -2, 8, -3, -3, -1, -2, -1, -2, -1, -2, -2, 2, -3},
+		{-1, -3, -3, -3, -1, -3, -3, -4, -3, 4, 2, -3, 1, 0, -3, -2, -1, -3, -1, 3},
+		{-1, -2, -3, -4, -1, -2, -3, -4, -3, 2, 4, -2, 2, 0, -3, -2, -1, -2, -1, 1},
+		{-1, 2, 0, -1, -3, 1, 1, -2, -1, -3, -2, 5, -1, -3, -1, 0, -1, -3, -2, -2},
+		{-1, -1, -2, -3, -1, 0, -2, -3, -2, 1, 2, -1, 5, 0, -2, -1, -1, -1, -1, 1},
+		{-2, -3, -3, -3, -2, -3, -3, -3, -1, 0, 0, -3, 0, 6, -4, -2, -2, 1, 3, -1},
+		{-1, -2, -2, -1, -3, -1, -1, -2, -2, -3, -3, -1, -2, -4, 7, -1, -1, -4, -3, -2},
+		{1, -1, 1, 0, -1, 0, 0, 0, -1, -2, -2, 0, -1, -2, -1, 4, 1, -3, -2, -2},
+		{0, -1, 0, -1, -1, -1, -1, -2, -2, -1, -1, -1, -1, -2, -1, 1, 5, -2, -2, 0},
+		{-3, -3, -4, -4, -2, -2, -3, -2, -2, -3, -2, -3, -1, 1, -4, -3, -2, 11, 2, -3},
+		{-2, -2, -2, -3, -2, -1, -2, -3, 2, -1, -1, -2, -1, 3, -3, -2, -2, 2, 7, -1},
+		{0, -3, -3, -3, -1, -2, -2, -3, -3, 3, 1, -2, 1, -1, -2, -2, 0, -3, -1, 4},
+	})
+	return m
+}
+
+// SumOfPairsScore adds up, over every pair of sequences, the matrix scores
+// of their aligned residues and affine gap scores: gapOpen for the first
+// position of each gap and gapExtend for every further one, both added to
+// the total (so normally negative). Columns where both members of a pair
+// are gaps are skipped for that pair, and residue pairs outside the matrix
+// alphabet score 0.
+func (s SequenceDB) SumOfPairsScore(matrix *SubstitutionMatrix, gapOpen, gapExtend int) (int, error) {
+	if !s.aligned {
+		return 0, ErrNotAligned
+	}
+	if matrix.alphabet != s.alphabet {
+		return 0, fmt.Errorf("matrix is for alphabet %q, alignment is %q", matrix.alphabet, s.alphabet)
+	}
+	total := 0
+	for i := 0; i < len(s.sequences); i++ {
+		for j := i + 1; j < len(s.sequences); j++ {
+			a, b := s.sequences[i].sequence, s.sequences[j].sequence
+			inGapA, inGapB := false, false
+			for col := 0; col < s.length; col++ {
+				gapA, gapB := isGap(a[col]), isGap(b[col])
+				switch {
+				case gapA && gapB:
+					continue
+				case gapA:
+					if inGapA {
+						total += gapExtend
+					} else {
+						total += gapOpen
+					}
+				case gapB:
+					if inGapB {
+						total += gapExtend
+					} else {
+						total += gapOpen
+					}
+				default:
+					sc, _ := matrix.Score(a[col], b[col])
+					total += sc
+				}
+				inGapA, inGapB = gapA, gapB
+			}
+		}
+	}
+	return total, nil
+}
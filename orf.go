@@ -0,0 +1,93 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ORF is an open reading frame located on the ungapped sequence. Start and
+// End are 0-based, half-open and on forward-strand coordinates whatever the
+// strand, and End includes the stop codon.
+type ORF struct {
+	Start   int
+	End     int
+	Strand  byte // '+' or '-'
+	Frame   int  // 0-2, offset of the reading frame from the 5' end of its strand
+	Protein string
+}
+
+// FindORFs finds ATG-to-stop open reading frames of at least minLength
+// codons, not counting the stop, on both strands of the ungapped sequence.
+// Each stop closes the longest ORF, so nested ATGs are not reported.
+func (s Sequence) FindORFs(minLength int, table int) ([]ORF, error) {
+	if s.alphabet != Nucleotide {
+		return nil, fmt.Errorf("cannot find ORFs in %q, not a nucleotide sequence", s.name)
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return nil, err
+	}
+	fwd := strings.ToUpper(ungapped(s.sequence))
+	L := len(fwd)
+	var orfs []ORF
+	for _, strand := range []byte{'+', '-'} {
+		seq := fwd
+		if strand == '-' {
+			seq = reverseComplement(fwd)
+		}
+		for frame := 0; frame < 3; frame++ {
+			start := -1
+			for i := frame; i+3 <= L; i += 3 {
+				codon := seq[i : i+3]
+				if start < 0 && codon == "ATG" {
+					start = i
+				} else if start >= 0 && code.IsStop(codon) {
+					if (i-start)/3 >= minLength {
+						orf := ORF{Start: start, End: i + 3, Strand: strand, Frame: frame, Protein: code.translate(seq[start:i], 0)}
+						if strand == '-' {
+							orf.Start, orf.End = L-(i+3), L-start
+						}
+						orfs = append(orfs, orf)
+					}
+					start = -1
+				}
+			}
+		}
+	}
+	sort.Slice(orfs, func(i, j int) bool {
+		if orfs[i].Start != orfs[j].Start {
+			return orfs[i].Start < orfs[j].Start
+		}
+		return orfs[i].Strand < orfs[j].Strand
+	})
+	return orfs, nil
+}
+
+// WriteORFsGFF writes ORFs as GFF3 CDS features on seqName, with 1-based
+// inclusive coordinates. ORFs begin at their first codon so the phase is
+// always 0.
+func WriteORFsGFF(w io.Writer, seqName string, orfs []ORF) error {
+	if seqName == "" {
+		return errors.New("GFF features need a sequence name")
+	}
+	if _, err := io.WriteString(w, "##gff-version 3\n"); err != nil {
+		return err
+	}
+	for i, orf := range orfs {
+		if orf.Start < 0 || orf.End <= orf.Start || (orf.End-orf.Start)%3 != 0 {
+			return fmt.Errorf("ORF %d spans [%d, %d), not a whole number of codons", i+1, orf.Start, orf.End)
+		}
+		if orf.Strand != '+' && orf.Strand != '-' {
+			return fmt.Errorf("ORF %d has strand %q, expected '+' or '-'", i+1, orf.Strand)
+		}
+		_, err := fmt.Fprintf(w, "%s\tgochujang\tCDS\t%d\t%d\t.\t%c\t0\tID=%s_orf%d\n",
+			seqName, orf.Start+1, orf.End, orf.Strand, seqName, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package gochujang
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// MKF on the forward strand, frame 2, then MP on the reverse strand from the
+// reverse complement of ATGCCCTGA at the 3' end
+const orfTestSeq = "CCATGAAATTTTAAGGTCAGGGCAT"
+
+func TestFindORFs(t *testing.T) {
+	seq := &Sequence{alphabet: Nucleotide, name: "chr1", sequence: orfTestSeq}
+	want := []ORF{
+		{Start: 2, End: 14, Strand: '+', Frame: 2, Protein: "MKF"},
+		{Start: 16, End: 25, Strand: '-', Frame: 0, Protein: "MP"},
+	}
+	orfs, err := seq.FindORFs(2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(orfs, want) {
+		t.Errorf("FindORFs(2) = %+v, want %+v", orfs, want)
+	}
+	if orfs, _ := seq.FindORFs(3, 1); !reflect.DeepEqual(orfs, want[:1]) {
+		t.Errorf("FindORFs(3) = %+v, want %+v", orfs, want[:1])
+	}
+}
+
+func TestWriteORFsGFF(t *testing.T) {
+	orfs := []ORF{
+		{Start: 2, End: 14, Strand: '+', Frame: 2, Protein: "MKF"},
+		{Start: 16, End: 25, Strand: '-', Frame: 0, Protein: "MP"},
+	}
+	var buf bytes.Buffer
+	if err := WriteORFsGFF(&buf, "chr1", orfs); err != nil {
+		t.Fatal(err)
+	}
+	want := "##gff-version 3\n" +
+		"chr1\tgochujang\tCDS\t3\t14\t.\t+\t0\tID=chr1_orf1\n" +
+		"chr1\tgochujang\tCDS\t17\t25\t.\t-\t0\tID=chr1_orf2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteORFsGFF =\n%s\nwant\n%s", got, want)
+	}
+
+	for _, bad := range []ORF{{Start: 0, End: 4, Strand: '+'}, {Start: 0, End: 3, Strand: '.'}} {
+		if err := WriteORFsGFF(&buf, "chr1", []ORF{bad}); err == nil {
+			t.Errorf("WriteORFsGFF accepted %+v", bad)
+		}
+	}
+}
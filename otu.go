@@ -0,0 +1,88 @@
+package gochujang
+
+import (
+	"fmt"
+	"sort"
+)
+
+// alignedIdentity aligns two unaligned sequences with Needleman-Wunsch
+// (match 1, mismatch -1, gap -2) and returns the fractional identity over the
+// alignment columns between the first and last column where both have a
+// residue, so end gaps from differing lengths do not count against it.
+func alignedIdentity(a, b string) float64 {
+	pairs := globalAlign(len(a), len(b), func(i, j int) int {
+		if upperByte(a[i]) == upperByte(b[j]) {
+			return 1
+		}
+		return -1
+	}, -2)
+	first, last := -1, -1
+	for k, p := range pairs {
+		if p[0] >= 0 && p[1] >= 0 {
+			if first < 0 {
+				first = k
+			}
+			last = k
+		}
+	}
+	if first < 0 {
+		return 0
+	}
+	same := 0
+	for _, p := range pairs[first : last+1] {
+		if p[0] >= 0 && p[1] >= 0 && upperByte(a[p[0]]) == upperByte(b[p[1]]) {
+			same++
+		}
+	}
+	return float64(same) / float64(last-first+1)
+}
+
+// ClusterOTUs clusters sequences greedily around centroids, as UCLUST and
+// VSEARCH do: longest first (by ungapped length, ties in DB order), each
+// sequence joins the first centroid whose identity to it is at least
+// identityThreshold, a fraction in [0,1] like VSEARCH's --id, or becomes a
+// centroid itself. Identity is PercentIdentity/100 for an aligned DB and a
+// global alignment otherwise. The map takes every name to its centroid's
+// name.
+func (s SequenceDB) ClusterOTUs(identityThreshold float64) (map[string]string, error) {
+	if identityThreshold < 0 || identityThreshold > 1 {
+		return nil, fmt.Errorf("identity threshold %g is not a fraction in [0,1]", identityThreshold)
+	}
+	order := make([]*Sequence, len(s.sequences))
+	copy(order, s.sequences)
+	residues := make(map[*Sequence]string, len(order))
+	for _, v := range order {
+		residues[v] = ungapped(v.sequence)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(residues[order[i]]) > len(residues[order[j]])
+	})
+
+	otus := make(map[string]string, len(order))
+	var centroids []*Sequence
+	for _, v := range order {
+		assigned := false
+		for _, c := range centroids {
+			var id float64
+			if s.aligned {
+				var err error
+				if id, err = PercentIdentity(c, v); err != nil {
+					continue // no shared columns, cannot be the same OTU
+				}
+				id /= 100
+			} else {
+				id = alignedIdentity(residues[c], residues[v])
+			}
+			if id >= identityThreshold {
+				otus[v.name] = c.name
+				assigned = true
+				break
+			}
+		}
+		if !assigned {
+			centroids = append(centroids, v)
+			otus[v.name] = v.name
+		}
+	}
+	return otus, nil
+}
@@ -0,0 +1,30 @@
+package gochujang
+
+import "testing"
+
+func TestClusterOTUs(t *testing.T) {
+	// c and b differ from a at 1 and 2 of 10 sites
+	db := testDB(t, ">a\nACGTACGTAC\n>b\nACGTACGTTT\n>c\nACGTACGTAA\n")
+	tests := []struct {
+		threshold float64
+		want      map[string]string
+	}{
+		{0.97, map[string]string{"a": "a", "b": "b", "c": "c"}},
+		{0.9, map[string]string{"a": "a", "b": "b", "c": "a"}},
+		{0.7, map[string]string{"a": "a", "b": "a", "c": "a"}},
+	}
+	for _, tt := range tests {
+		otus, err := db.ClusterOTUs(tt.threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range tt.want {
+			if otus[name] != want {
+				t.Errorf("threshold %g: %s -> %s, want %s", tt.threshold, name, otus[name], want)
+			}
+		}
+	}
+	if _, err := db.ClusterOTUs(97); err == nil {
+		t.Error("percent threshold accepted")
+	}
+}
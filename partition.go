@@ -0,0 +1,158 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	nexusComment = regexp.MustCompile(`\[[^\]]*\]`)
+	rangeSpacing = regexp.MustCompile(`\s*([-\\])\s*`)
+)
+
+// SplitByPartitionFile cuts the alignment into one sub-alignment per charset
+// of a partition file, either RAxML style ("DNA, gene1 = 1-500, 501-900\3")
+// or the charset lines of a NEXUS sets block ("charset gene1 = 1-500;").
+// Ranges are 1-based and inclusive, "\n" takes every nth column and "." in
+// NEXUS means the last column.
+func (s SequenceDB) SplitByPartitionFile(path string) (map[string]SequenceDB, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := nexusComment.ReplaceAllString(string(raw), "")
+
+	var defs []string // "name = ranges" for every charset
+	if strings.Contains(strings.ToLower(text), "charset") {
+		for _, stmt := range strings.Split(text, ";") {
+			stmt = strings.TrimSpace(stmt)
+			if fields := strings.Fields(stmt); len(fields) > 0 && strings.EqualFold(fields[0], "charset") {
+				defs = append(defs, strings.TrimSpace(stmt[len(fields[0]):]))
+			}
+		}
+	} else {
+		for _, line := range strings.Split(text, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			eq := strings.Index(line, "=")
+			if eq < 0 {
+				return nil, fmt.Errorf("partition line %q has no '='", line)
+			}
+			left := line[:eq]
+			if comma := strings.LastIndex(left, ","); comma >= 0 {
+				left = left[comma+1:] // drop the model/datatype
+			}
+			defs = append(defs, left+"="+line[eq+1:])
+		}
+	}
+	if len(defs) == 0 {
+		return nil, errors.New("no partitions found")
+	}
+
+	parts := make(map[string]SequenceDB)
+	for _, def := range defs {
+		eq := strings.Index(def, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("charset %q has no '='", def)
+		}
+		name := strings.Trim(strings.TrimSpace(def[:eq]), `'"`)
+		if name == "" {
+			return nil, fmt.Errorf("charset %q has no name", def)
+		}
+		if _, dup := parts[name]; dup {
+			return nil, fmt.Errorf("partition %q is defined twice", name)
+		}
+		cols, err := s.partitionColumns(def[eq+1:])
+		if err != nil {
+			return nil, fmt.Errorf("partition %q: %w", name, err)
+		}
+		parts[name] = s.selectColumns(cols)
+	}
+	return parts, nil
+}
+
+// partitionColumns turns a list of ranges such as "1-100, 201-300\3" into
+// sorted 0-based columns.
+func (s SequenceDB) partitionColumns(spec string) ([]int, error) {
+	spec = rangeSpacing.ReplaceAllString(spec, "$1")
+	seen := make(map[int]bool)
+	var cols []int
+	for _, tok := range strings.Fields(strings.ReplaceAll(spec, ",", " ")) {
+		step := 1
+		if slash := strings.Index(tok, `\`); slash >= 0 {
+			var err error
+			if step, err = strconv.Atoi(tok[slash+1:]); err != nil || step < 1 {
+				return nil, fmt.Errorf("bad step in %q", tok)
+			}
+			tok = tok[:slash]
+		}
+		bounds := strings.SplitN(tok, "-", 2)
+		start, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("bad range %q", tok)
+		}
+		end := start
+		if len(bounds) == 2 {
+			if bounds[1] == "." {
+				end = s.length
+			} else if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("bad range %q", tok)
+			}
+		}
+		if start < 1 || end < start || end > s.length {
+			return nil, fmt.Errorf("range %q is outside the alignment of %d columns", tok, s.length)
+		}
+		for col := start; col <= end; col += step {
+			if !seen[col-1] {
+				seen[col-1] = true
+				cols = append(cols, col-1)
+			}
+		}
+	}
+	if len(cols) == 0 {
+		return nil, errors.New("no columns")
+	}
+	sort.Ints(cols)
+	return cols, nil
+}
+
+// OccupancyMatrix tabulates which taxa are present in which gene alignments
+// of a supermatrix: present[i][j] tells whether taxa[i] has any non-gap
+// character in genes[j]. Taxa and genes are sorted by name.
+func OccupancyMatrix(dbs map[string]SequenceDB) (taxa []string, genes []string, present [][]bool, err error) {
+	if len(dbs) == 0 {
+		return nil, nil, nil, errors.New("no gene alignments given")
+	}
+	occupied := make(map[string]map[string]bool) // taxon -> gene -> present
+	for gene, db := range dbs {
+		genes = append(genes, gene)
+		for _, v := range db.sequences {
+			if occupied[v.name] == nil {
+				occupied[v.name] = make(map[string]bool)
+				taxa = append(taxa, v.name)
+			}
+			if len(ungapped(v.sequence)) > 0 {
+				occupied[v.name][gene] = true
+			}
+		}
+	}
+	sort.Strings(taxa)
+	sort.Strings(genes)
+	present = make([][]bool, len(taxa))
+	for i, taxon := range taxa {
+		present[i] = make([]bool, len(genes))
+		for j, gene := range genes {
+			present[i][j] = occupied[taxon][gene]
+		}
+	}
+	return taxa, genes, present, nil
+}
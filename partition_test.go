@@ -0,0 +1,48 @@
+package gochujang
+
+import "testing"
+
+func TestSplitByPartitionFile(t *testing.T) {
+	db := testDB(t, ">a\nAACCGGTTAC\n>b\nAACCGGTTAG\n")
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string // sub-alignment of a
+	}{
+		{
+			name:    "raxml",
+			content: "DNA, g1 = 1-4\nDNA, g2 = 5-8, 10\nDNA, codon3 = 3-10\\3\n",
+			want:    map[string]string{"g1": "AACC", "g2": "GGTTC", "codon3": "CGA"},
+		},
+		{
+			name:    "nexus",
+			content: "#NEXUS\nbegin sets;\n  charset g1 = 1-4; [first gene]\n  charset rest = 5 - .;\nend;\n",
+			want:    map[string]string{"g1": "AACC", "rest": "GGTTAC"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := db.SplitByPartitionFile(writeTestFile(t, "parts.txt", tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(parts) != len(tt.want) {
+				t.Fatalf("got %d partitions, want %d", len(parts), len(tt.want))
+			}
+			for name, want := range tt.want {
+				part, ok := parts[name]
+				if !ok {
+					t.Errorf("missing partition %q", name)
+					continue
+				}
+				if got := part.sequences[0].sequence; got != want {
+					t.Errorf("partition %q = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+
+	if _, err := db.SplitByPartitionFile(writeTestFile(t, "bad.txt", "DNA, g1 = 1-11\n")); err == nil {
+		t.Error("range past the alignment end should give an error")
+	}
+}
@@ -0,0 +1,57 @@
+package gochujang
+
+// PatternIndex is the site-pattern compression of an alignment: its unique
+// columns in order of first appearance, how often each occurs, and which
+// pattern every original column maps to.
+type PatternIndex struct {
+	patterns      []string
+	weights       []int
+	columnPattern []int
+}
+
+// BuildPatternIndex compresses the alignment into unique column patterns.
+// Columns are compared character for character, so case and the gap symbol
+// used matter.
+func (s SequenceDB) BuildPatternIndex() (*PatternIndex, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	p := &PatternIndex{columnPattern: make([]int, s.length)}
+	seen := make(map[string]int)
+	col := make([]byte, len(s.sequences))
+	for i := 0; i < s.length; i++ {
+		for j, v := range s.sequences {
+			col[j] = v.sequence[i]
+		}
+		idx, ok := seen[string(col)]
+		if !ok {
+			idx = len(p.patterns)
+			seen[string(col)] = idx
+			p.patterns = append(p.patterns, string(col))
+			p.weights = append(p.weights, 0)
+		}
+		p.weights[idx]++
+		p.columnPattern[i] = idx
+	}
+	return p, nil
+}
+
+// Len is the number of unique patterns.
+func (p *PatternIndex) Len() int {
+	return len(p.patterns)
+}
+
+// Pattern returns pattern i, one character per sequence in DB order.
+func (p *PatternIndex) Pattern(i int) string {
+	return p.patterns[i]
+}
+
+// Weight returns the number of alignment columns showing pattern i.
+func (p *PatternIndex) Weight(i int) int {
+	return p.weights[i]
+}
+
+// ColumnPattern returns the index of the pattern at alignment column col.
+func (p *PatternIndex) ColumnPattern(col int) int {
+	return p.columnPattern[col]
+}
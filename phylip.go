@@ -0,0 +1,136 @@
+package gochujang
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadPhylipFromFile reads a sequential or interleaved PHYLIP alignment.
+// Strict files (names padded to 10 columns) and relaxed files (names of any
+// length separated from the sequence by whitespace) are both accepted; the
+// layout is whichever one splits every taxon line into a name and a
+// sequence of the length declared in the header.
+func ReadPhylipFromFile(path string) (seqs SequenceDB) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" { // blank lines only separate interleaved blocks
+			lines = append(lines, scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	if len(lines) == 0 {
+		log.Fatal("PHYLIP file is empty!")
+	}
+
+	header := strings.Fields(lines[0])
+	if len(header) < 2 {
+		log.Fatal("PHYLIP header should give the number of taxa and characters!")
+	}
+	ntax, err := strconv.Atoi(header[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	nchar, err := strconv.Atoi(header[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ntax < 1 || len(lines)-1 < ntax {
+		log.Fatal("PHYLIP file has fewer taxa than its header declares!")
+	}
+
+	var names, residues []string
+	ok := false
+	for _, layout := range []struct{ interleaved, relaxed bool }{
+		{true, false}, {true, true}, {false, false}, {false, true},
+	} {
+		if names, residues, ok = splitPhylip(lines[1:], ntax, nchar, layout.interleaved, layout.relaxed); ok {
+			break
+		}
+	}
+	if !ok {
+		log.Fatal("could not split PHYLIP lines into names and sequences of the declared length!")
+	}
+
+	for i := range names {
+		seq := NewSequence()
+		seq.name = names[i]
+		seq.sequence = residues[i]
+		seqs.sequences = append(seqs.sequences, seq)
+	}
+	if err := seqs.Refresh(); err != nil {
+		log.Fatal(err)
+	}
+	return
+}
+
+// splitTaxonLine splits the first line of a taxon into its name and the
+// residues that follow it, strict names taking exactly 10 columns.
+func splitTaxonLine(line string, relaxed bool) (name, residues string, ok bool) {
+	if relaxed {
+		fields := strings.Fields(line)
+		name, residues = fields[0], strings.Join(fields[1:], "")
+	} else {
+		if len(line) < 10 {
+			return "", "", false
+		}
+		name, residues = strings.TrimSpace(line[:10]), strings.Join(strings.Fields(line[10:]), "")
+	}
+	return name, residues, name != ""
+}
+
+// splitPhylip partitions the taxon lines of a PHYLIP file using either the
+// strict or the relaxed naming, reporting whether every sequence came out at
+// nchar. Interleaved files give one named line per taxon and then blocks of
+// unnamed lines in the same taxon order; sequential files give each taxon's
+// named line followed by unnamed lines until it reaches nchar.
+func splitPhylip(lines []string, ntax, nchar int, interleaved, relaxed bool) (names, residues []string, ok bool) {
+	names = make([]string, ntax)
+	residues = make([]string, ntax)
+	if interleaved {
+		if len(lines) < ntax {
+			return nil, nil, false
+		}
+		for i := 0; i < ntax; i++ {
+			if names[i], residues[i], ok = splitTaxonLine(lines[i], relaxed); !ok {
+				return nil, nil, false
+			}
+		}
+		for i, line := range lines[ntax:] {
+			residues[i%ntax] += strings.Join(strings.Fields(line), "")
+		}
+	} else {
+		next := 0
+		for i := 0; i < ntax; i++ {
+			if next >= len(lines) {
+				return nil, nil, false
+			}
+			if names[i], residues[i], ok = splitTaxonLine(lines[next], relaxed); !ok {
+				return nil, nil, false
+			}
+			for next++; len(residues[i]) < nchar && next < len(lines); next++ {
+				residues[i] += strings.Join(strings.Fields(lines[next]), "")
+			}
+		}
+		if next != len(lines) {
+			return nil, nil, false
+		}
+	}
+	for _, r := range residues {
+		if len(r) != nchar {
+			return nil, nil, false
+		}
+	}
+	return names, residues, true
+}
@@ -0,0 +1,66 @@
+package gochujang
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadPhylipFromFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+	}{
+		{
+			name:    "strict",
+			content: "2 8\nHomo_sap  ACGTACGT\nPan_trog  ACGT ACGA\n",
+			want:    map[string]string{"Homo_sap": "ACGTACGT", "Pan_trog": "ACGTACGA"},
+		},
+		{
+			name:    "relaxed",
+			content: "2 8\nHomo_sapiens_long ACGT ACGT\nPan_troglodytes ACGTACGA\n",
+			want:    map[string]string{"Homo_sapiens_long": "ACGTACGT", "Pan_troglodytes": "ACGTACGA"},
+		},
+		{
+			name:    "interleaved",
+			content: "2 8\nHomo_sapiens_long ACGT\nPan ACGT\n\nACGT\nACGA\n",
+			want:    map[string]string{"Homo_sapiens_long": "ACGTACGT", "Pan": "ACGTACGA"},
+		},
+		{
+			name:    "sequential wrapped",
+			content: "2 12\nlongname_one\nACGTAC\nGTACGT\nlongname_two ACGTAC\nGTACGA\n",
+			want:    map[string]string{"longname_one": "ACGTACGTACGT", "longname_two": "ACGTACGTACGA"},
+		},
+		{
+			name:    "sequential strict",
+			content: "3 6\nalpha     ACG\nTAC\nbeta      ACGTAA\ngamma     A\nCGTAG\n",
+			want:    map[string]string{"alpha": "ACGTAC", "beta": "ACGTAA", "gamma": "ACGTAG"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := ReadPhylipFromFile(writeTestFile(t, "aln.phy", tt.content))
+			if len(db.sequences) != len(tt.want) {
+				t.Fatalf("got %d sequences, want %d", len(db.sequences), len(tt.want))
+			}
+			for _, v := range db.sequences {
+				if want, ok := tt.want[v.name]; !ok || v.sequence != want {
+					t.Errorf("%q = %q, want %q", v.name, v.sequence, want)
+				}
+			}
+			if !db.aligned || db.length != len(db.sequences[0].sequence) {
+				t.Errorf("aligned = %v, length = %d", db.aligned, db.length)
+			}
+		})
+	}
+}
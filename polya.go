@@ -0,0 +1,34 @@
+package gochujang
+
+import "fmt"
+
+// TrimPolyA removes a 3' poly-A tail of at least minLen bases or, failing
+// that, the 5' poly-T head it becomes on a reverse-complemented transcript.
+// It returns the number of bases removed.
+func (s *Sequence) TrimPolyA(minLen int) (trimmed int, err error) {
+	if s.alphabet != Nucleotide {
+		return 0, fmt.Errorf("cannot trim poly-A in %q, not a nucleotide sequence", s.name)
+	}
+	if minLen < 1 {
+		return 0, fmt.Errorf("minimum tail length must be at least 1, got %d", minLen)
+	}
+	end := len(s.sequence)
+	for end > 0 && upperByte(s.sequence[end-1]) == 'A' {
+		end--
+	}
+	if tail := len(s.sequence) - end; tail >= minLen {
+		s.sequence = s.sequence[:end]
+		s.CalcBF()
+		return tail, nil
+	}
+	start := 0
+	for start < len(s.sequence) && upperByte(s.sequence[start]) == 'T' {
+		start++
+	}
+	if start >= minLen {
+		s.sequence = s.sequence[start:]
+		s.CalcBF()
+		return start, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,141 @@
+package gochujang
+
+import "errors"
+
+// columnMAFs gives the minor allele frequency of every column of a
+// nucleotide alignment, taking the second most common base as the minor
+// allele and ignoring gaps and ambiguity codes. Invariant columns get 0.
+func (s SequenceDB) columnMAFs() ([]float64, error) {
+	if s.alphabet != Nucleotide {
+		return nil, errors.New("minor allele frequencies need a nucleotide alignment")
+	}
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	mafs := make([]float64, len(counts))
+	for pos, col := range counts {
+		major, minor, tot := 0, 0, 0
+		for _, c := range col {
+			tot += c
+			if c > major {
+				major, minor = c, major
+			} else if c > minor {
+				minor = c
+			}
+		}
+		if tot > 0 {
+			mafs[pos] = float64(minor) / float64(tot)
+		}
+	}
+	return mafs, nil
+}
+
+// MinorAlleleFreqs returns the variable columns of a nucleotide alignment
+// and their minor allele frequencies. For sites with more than two alleles
+// the second most common base counts as the minor allele.
+func (s SequenceDB) MinorAlleleFreqs() (positions []int, mafs []float64, err error) {
+	all, err := s.columnMAFs()
+	if err != nil {
+		return nil, nil, err
+	}
+	for pos, maf := range all {
+		if maf > 0 {
+			positions = append(positions, pos)
+			mafs = append(mafs, maf)
+		}
+	}
+	return positions, mafs, nil
+}
+
+// FilterByMAF keeps the columns whose minor allele frequency is at least
+// minMAF, so any positive minMAF also drops invariant columns.
+func (s SequenceDB) FilterByMAF(minMAF float64) (SequenceDB, error) {
+	mafs, err := s.columnMAFs()
+	if err != nil {
+		return SequenceDB{}, err
+	}
+	var keep []int
+	for pos, maf := range mafs {
+		if maf >= minMAF {
+			keep = append(keep, pos)
+		}
+	}
+	return s.selectColumns(keep), nil
+}
+
+// ExpectedHeterozygosity gives the unbiased gene diversity of every column,
+// n/(n-1) * (1 - sum of squared residue frequencies) over its n residues.
+// Invariant columns and columns with fewer than two residues give 0.
+func (s SequenceDB) ExpectedHeterozygosity() ([]float64, error) {
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	het := make([]float64, len(counts))
+	for pos, col := range counts {
+		n := 0
+		for _, c := range col {
+			n += c
+		}
+		if n < 2 {
+			continue
+		}
+		homo := 0.0
+		for _, c := range col {
+			p := float64(c) / float64(n)
+			homo += p * p
+		}
+		het[pos] = float64(n) / float64(n-1) * (1 - homo)
+	}
+	return het, nil
+}
+
+// segregatingSites counts the columns where every sequence has a residue
+// (complete deletion of gaps and ambiguity codes), and how many of those
+// are segregating.
+func (s SequenceDB) segregatingSites() (segregating, sites int, err error) {
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, col := range counts {
+		n, distinct := 0, 0
+		for _, c := range col {
+			n += c
+			if c > 0 {
+				distinct++
+			}
+		}
+		if n < len(s.sequences) {
+			continue
+		}
+		sites++
+		if distinct > 1 {
+			segregating++
+		}
+	}
+	return segregating, sites, nil
+}
+
+// WattersonTheta is Watterson's estimator of theta per site, S / a1 / L,
+// where a1 is the harmonic number for n-1 sequences and sites with gaps or
+// ambiguity codes are left out of both S and L.
+func (s SequenceDB) WattersonTheta() (float64, error) {
+	n := len(s.sequences)
+	if n < 2 {
+		return 0, errors.New("need at least two sequences")
+	}
+	segregating, sites, err := s.segregatingSites()
+	if err != nil {
+		return 0, err
+	}
+	if sites == 0 {
+		return 0, errors.New("no sites without gaps or ambiguity codes")
+	}
+	a1 := 0.0
+	for i := 1; i < n; i++ {
+		a1 += 1 / float64(i)
+	}
+	return float64(segregating) / a1 / float64(sites), nil
+}
@@ -0,0 +1,49 @@
+package gochujang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMinorAlleleFreqs(t *testing.T) {
+	// column 0 is invariant, 1 has one C in four, 2 ignores its gap and 3
+	// has three alleles, two G and one each of A and T
+	db := testDB(t, ">a\nAAAA\n>b\nAACG\n>c\nAA-G\n>d\nACCT\n")
+	positions, mafs, err := db.MinorAlleleFreqs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %v, want %v", positions, want)
+	}
+	if want := []float64{0.25, 1.0 / 3, 0.25}; !reflect.DeepEqual(mafs, want) {
+		t.Errorf("MAFs = %v, want %v", mafs, want)
+	}
+}
+
+func TestFilterByMAF(t *testing.T) {
+	db := testDB(t, ">a\nAAAA\n>b\nAACG\n>c\nAA-G\n>d\nACCT\n")
+	tests := []struct {
+		minMAF float64
+		want   []string
+	}{
+		{0, []string{"AAAA", "AACG", "AA-G", "ACCT"}},
+		{0.25, []string{"AAA", "ACG", "A-G", "CCT"}},
+		{0.3, []string{"A", "C", "-", "C"}},
+		{0.5, []string{"", "", "", ""}},
+	}
+	for _, tt := range tests {
+		filtered, err := db.FilterByMAF(tt.minMAF)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range filtered.sequences {
+			if v.name != db.sequences[i].name || v.sequence != tt.want[i] {
+				t.Errorf("FilterByMAF(%g): %s = %q, want %q", tt.minMAF, v.name, v.sequence, tt.want[i])
+			}
+		}
+	}
+	if _, err := testDB(t, ">a\nMKV\n>b\nMRV\n").FilterByMAF(0.1); err == nil {
+		t.Error("protein alignment filtered by MAF")
+	}
+}
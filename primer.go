@@ -0,0 +1,175 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// SantaLucia (1998) unified nearest-neighbour parameters for each top-strand
+// dinucleotide, as enthalpy (kcal/mol) and entropy (cal/K/mol)
+var nnParams = map[string][2]float64{
+	"AA": {-7.9, -22.2}, "TT": {-7.9, -22.2},
+	"AT": {-7.2, -20.4},
+	"TA": {-7.2, -21.3},
+	"CA": {-8.5, -22.7}, "TG": {-8.5, -22.7},
+	"GT": {-8.4, -22.4}, "AC": {-8.4, -22.4},
+	"CT": {-7.8, -21.0}, "AG": {-7.8, -21.0},
+	"GA": {-8.2, -22.2}, "TC": {-8.2, -22.2},
+	"CG": {-10.6, -27.2},
+	"GC": {-9.8, -24.4},
+	"GG": {-8.0, -19.9}, "CC": {-8.0, -19.9},
+}
+
+// conditions assumed by the nearest-neighbour Tm, as in Primer3's defaults
+const (
+	oligoConc     = 50e-9 // M
+	sodiumConc    = 0.05  // M
+	maxWallaceLen = 20
+	maxNNLen      = 60
+)
+
+// MeltingTemp estimates the melting temperature in °C of a short oligo,
+// gaps removed, by method "wallace" (2°C per A/T and 4°C per G/C, for up to
+// 20 nt) or "nn" (SantaLucia 1998 nearest neighbours at 50 nM oligo and
+// 50 mM Na+, for up to 60 nt). Only unambiguous bases are accepted.
+func (s Sequence) MeltingTemp(method string) (float64, error) {
+	oligo := strings.ToUpper(ungapped(s.sequence))
+	if len(oligo) < 2 {
+		return 0, fmt.Errorf("%q is too short for a melting temperature", s.name)
+	}
+	at, gc := 0, 0
+	for i := 0; i < len(oligo); i++ {
+		switch oligo[i] {
+		case 'A', 'T':
+			at++
+		case 'G', 'C':
+			gc++
+		default:
+			return 0, fmt.Errorf("%q has %q, only A, C, G and T are allowed", s.name, oligo[i])
+		}
+	}
+
+	switch method {
+	case "wallace":
+		if len(oligo) > maxWallaceLen {
+			return 0, fmt.Errorf("%q is longer than %d nt, the Wallace rule does not hold", s.name, maxWallaceLen)
+		}
+		return float64(2*at + 4*gc), nil
+	case "nn":
+		if len(oligo) > maxNNLen {
+			return 0, fmt.Errorf("%q is longer than %d nt, too long for nearest-neighbour Tm", s.name, maxNNLen)
+		}
+		dH, dS := 0.0, 0.0
+		for _, end := range []byte{oligo[0], oligo[len(oligo)-1]} { // initiation by terminal pair
+			if end == 'G' || end == 'C' {
+				dH += 0.1
+				dS += -2.8
+			} else {
+				dH += 2.3
+				dS += 4.1
+			}
+		}
+		for i := 0; i+1 < len(oligo); i++ {
+			p := nnParams[oligo[i:i+2]]
+			dH += p[0]
+			dS += p[1]
+		}
+		ct := oligoConc / 4
+		if oligo == reverseComplement(oligo) {
+			dS += -1.4 // symmetry correction
+			ct = oligoConc
+		}
+		dS += 0.368 * float64(len(oligo)-1) * math.Log(sodiumConc)
+		const R = 1.987 // cal/K/mol
+		return 1000*dH/(dS+R*math.Log(ct)) - 273.15, nil
+	default:
+		return 0, fmt.Errorf("unknown melting temperature method %q", method)
+	}
+}
+
+// basesMatch compares two residues ignoring case; with iupac set, ambiguity
+// codes match any base they stand for (R matches A and G, N matches all).
+func basesMatch(x, y byte, iupac bool) bool {
+	x, y = upperByte(x), upperByte(y)
+	if x == y {
+		return true
+	}
+	if !iupac {
+		return false
+	}
+	xs, okx := iupacBases[x]
+	ys, oky := iupacBases[y]
+	return okx && oky && strings.ContainsAny(xs, ys)
+}
+
+// FindBestMatch slides query along the sequence and returns the leftmost
+// position with the fewest mismatches, if that is no more than maxMismatch.
+// Unless the sequence is a protein, IUPAC codes in either one match the
+// bases they stand for.
+func (s Sequence) FindBestMatch(query string, maxMismatch int) (pos int, mismatches int, found bool, err error) {
+	if query == "" {
+		return 0, 0, false, errors.New("empty query")
+	}
+	if maxMismatch < 0 {
+		return 0, 0, false, errors.New("maximum mismatches cannot be negative")
+	}
+	if len(query) > len(s.sequence) {
+		return 0, 0, false, fmt.Errorf("query is longer than %q", s.name)
+	}
+	iupac := s.alphabet != AminoAcid
+	pos, mismatches = -1, maxMismatch+1
+	for start := 0; start+len(query) <= len(s.sequence); start++ {
+		mm := 0
+		for i := 0; i < len(query) && mm < mismatches; i++ {
+			if !basesMatch(s.sequence[start+i], query[i], iupac) {
+				mm++
+			}
+		}
+		if mm < mismatches {
+			pos, mismatches = start, mm
+			if mm == 0 {
+				break
+			}
+		}
+	}
+	if pos < 0 {
+		return 0, 0, false, nil
+	}
+	return pos, mismatches, true, nil
+}
+
+// MaskPrimerRegions looks for each primer, and its reverse complement, with
+// FindBestMatch and hard-masks the better hit with N. It returns how many
+// primers were found and masked.
+func (s *Sequence) MaskPrimerRegions(primers []string, maxMismatch int) (int, error) {
+	if s.alphabet == AminoAcid {
+		return 0, fmt.Errorf("cannot mask primers in protein sequence %q", s.name)
+	}
+	residues := []byte(s.sequence)
+	masked := 0
+	for _, primer := range primers {
+		pos, mm, found, err := s.FindBestMatch(primer, maxMismatch)
+		if err != nil {
+			return masked, err
+		}
+		rcPos, rcMM, rcFound, err := s.FindBestMatch(reverseComplement(primer), maxMismatch)
+		if err != nil {
+			return masked, err
+		}
+		if rcFound && (!found || rcMM < mm) {
+			pos, found = rcPos, true
+		}
+		if !found {
+			continue
+		}
+		for i := pos; i < pos+len(primer); i++ {
+			residues[i] = 'N'
+		}
+		masked++
+	}
+	s.sequence = string(residues)
+	s.CalcBF()
+	return masked, nil
+}
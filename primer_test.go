@@ -0,0 +1,33 @@
+package gochujang
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeltingTemp(t *testing.T) {
+	oligo := &Sequence{name: "p", sequence: "CGTTGA", alphabet: Nucleotide}
+
+	wallace, err := oligo.MeltingTemp("wallace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wallace != 18 { // 3 A/T and 3 G/C
+		t.Errorf("Wallace Tm = %g, want 18", wallace)
+	}
+
+	// initiation C (0.1, -2.8) and A (2.3, 4.1), stacks CG GT TT TG GA, then
+	// 0.368*5*ln(0.05) of salt entropy and Ct = 50 nM / 4:
+	// dH = -41.2 kcal/mol, dS = -120.9121 cal/K/mol
+	nn, err := oligo.MeltingTemp("nn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(nn-(-10.84766)) > 1e-4 {
+		t.Errorf("nearest-neighbour Tm = %g, want -10.84766", nn)
+	}
+
+	if _, err := (&Sequence{name: "n", sequence: "ACGN"}).MeltingTemp("nn"); err == nil {
+		t.Error("ambiguous base should be rejected")
+	}
+}
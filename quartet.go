@@ -0,0 +1,37 @@
+package gochujang
+
+// QuartetSupport counts the sites supporting each resolution of the quartet
+// of four named taxa: a site supports ab|cd when a and b share one state, c
+// and d share another. Sites where any of the four has a gap or ambiguity
+// code are skipped.
+func (s SequenceDB) QuartetSupport(a, b, c, d string) (ab_cd, ac_bd, ad_bc int, err error) {
+	if !s.aligned {
+		return 0, 0, 0, ErrNotAligned
+	}
+	seqs, err := s.getSequences([]string{a, b, c, d})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	index := stateIndex(s.alphabet)
+	var st [4]int
+	for i := 0; i < s.length; i++ {
+		ok := true
+		for j, v := range seqs {
+			if st[j], ok = index[v.sequence[i]]; !ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		switch {
+		case st[0] == st[1] && st[2] == st[3] && st[0] != st[2]:
+			ab_cd++
+		case st[0] == st[2] && st[1] == st[3] && st[0] != st[1]:
+			ac_bd++
+		case st[0] == st[3] && st[1] == st[2] && st[0] != st[1]:
+			ad_bc++
+		}
+	}
+	return ab_cd, ac_bd, ad_bc, nil
+}
@@ -0,0 +1,152 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// issCritical holds Xia et al.'s (2003) critical values of the saturation
+// index for a symmetrical tree, by number of OTUs (rows: 4, 8, 16, 32) and
+// sequence length (columns: issLengths).
+var (
+	issOTUs     = []float64{4, 8, 16, 32}
+	issLengths  = []float64{250, 500, 1000, 2000, 4000}
+	issCritical = [][]float64{
+		{0.800, 0.830, 0.850, 0.860, 0.870},
+		{0.750, 0.790, 0.811, 0.830, 0.840},
+		{0.710, 0.750, 0.784, 0.800, 0.820},
+		{0.660, 0.710, 0.746, 0.770, 0.790},
+	}
+)
+
+// bracket returns the index i with grid[i] <= x <= grid[i+1] and the
+// fraction of the way x lies from grid[i] to grid[i+1] on a log scale.
+func bracket(grid []float64, x float64) (int, float64) {
+	i := 0
+	for i < len(grid)-2 && x > grid[i+1] {
+		i++
+	}
+	return i, math.Log(x/grid[i]) / math.Log(grid[i+1]/grid[i])
+}
+
+// criticalIss interpolates issCritical, on the logs of both the number of
+// OTUs and the sequence length, for ntax sequences of nsites columns.
+func criticalIss(ntax, nsites int) (float64, error) {
+	n, l := float64(ntax), float64(nsites)
+	if n < issOTUs[0] || n > issOTUs[len(issOTUs)-1] {
+		return 0, fmt.Errorf("no critical saturation index for %d sequences, only %g to %g", ntax, issOTUs[0], issOTUs[len(issOTUs)-1])
+	}
+	if l < issLengths[0] || l > issLengths[len(issLengths)-1] {
+		return 0, fmt.Errorf("no critical saturation index for %d sites, only %g to %g", nsites, issLengths[0], issLengths[len(issLengths)-1])
+	}
+	r, fr := bracket(issOTUs, n)
+	c, fc := bracket(issLengths, l)
+	at := func(row int) float64 {
+		return issCritical[row][c] + fc*(issCritical[row][c+1]-issCritical[row][c])
+	}
+	return at(r) + fr*(at(r+1)-at(r)), nil
+}
+
+// SaturationIndex computes Xia's index of substitution saturation (Xia et
+// al. 2003) for a nucleotide alignment: the mean site entropy over the mean
+// entropy expected at full substitution saturation, where every site is an
+// independent draw from the alignment's base frequencies. Gaps and ambiguity
+// codes are left out, so each site is compared against the saturated entropy
+// for its own number of residues; sites with fewer than two residues are
+// skipped. issc is Xia's critical value for a symmetrical tree with as many
+// sequences and columns as the alignment; the sequences are saturated when
+// iss is not clearly below it. Alignments outside the 4 to 32 sequences and
+// 250 to 4000 columns of Xia's table are an error.
+func (s SequenceDB) SaturationIndex() (iss, issc float64, err error) {
+	if s.alphabet != Nucleotide {
+		return 0, 0, errors.New("saturation index needs a nucleotide alignment")
+	}
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return 0, 0, err
+	}
+	if issc, err = criticalIss(len(s.sequences), s.length); err != nil {
+		return 0, 0, err
+	}
+
+	saturated := make(map[int]float64) // by number of residues at a site
+	h, hfss := 0.0, 0.0
+	sites := 0
+	for _, col := range counts {
+		n := 0
+		for _, c := range col {
+			n += c
+		}
+		if n < 2 {
+			continue
+		}
+		h += entropyOfCounts(col, n)
+		if _, done := saturated[n]; !done {
+			saturated[n] = expectedEntropy(s.BF, n)
+		}
+		hfss += saturated[n]
+		sites++
+	}
+	if sites == 0 || hfss == 0 {
+		return 0, 0, errors.New("no sites with at least two residues")
+	}
+	return h / hfss, issc, nil
+}
+
+func entropyOfCounts(counts []int, n int) float64 { // in bits
+	h := 0.0
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(n)
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+// expectedEntropy is the mean entropy of a site of n nucleotides drawn
+// independently with frequencies bf. It is summed exactly over all site
+// compositions for small n; past that the Miller-Madow expansion
+// H(bf) - 3/(2n ln 2) is accurate enough and far cheaper.
+func expectedEntropy(bf []float64, n int) float64 {
+	if n > 100 {
+		h := 0.0
+		for _, p := range bf {
+			if p > 0 {
+				h -= p * math.Log2(p)
+			}
+		}
+		return h - float64(len(bf)-1)/(2*float64(n)*math.Ln2)
+	}
+
+	lfact := make([]float64, n+1)
+	for i := 1; i <= n; i++ {
+		lfact[i] = lfact[i-1] + math.Log(float64(i))
+	}
+	logp := make([]float64, len(bf))
+	for i, p := range bf {
+		logp[i] = math.Log(p)
+	}
+	term := func(c, i int) float64 { // log of p_i^c / c!
+		if c == 0 {
+			return -lfact[0]
+		}
+		return float64(c)*logp[i] - lfact[c]
+	}
+	expected := 0.0
+	col := make([]int, 4)
+	for a := 0; a <= n; a++ {
+		for b := 0; a+b <= n; b++ {
+			for c := 0; a+b+c <= n; c++ {
+				col[0], col[1], col[2], col[3] = a, b, c, n-a-b-c
+				logProb := lfact[n] + term(col[0], 0) + term(col[1], 1) + term(col[2], 2) + term(col[3], 3)
+				if math.IsInf(logProb, -1) {
+					continue // composition impossible when a base is absent
+				}
+				expected += math.Exp(logProb) * entropyOfCounts(col, n)
+			}
+		}
+	}
+	return expected
+}
@@ -0,0 +1,47 @@
+package gochujang
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSaturationIndex(t *testing.T) {
+	fasta := func(seqs ...string) string {
+		var b strings.Builder
+		for i, v := range seqs {
+			b.WriteString(">s" + string(rune('a'+i)) + "\n" + v + "\n")
+		}
+		return b.String()
+	}
+	x := strings.Repeat("ACGT", 250)
+	y := strings.Repeat("CATG", 250)
+	tests := []struct {
+		name      string
+		fasta     string
+		iss, issc float64
+	}{
+		{"identical", fasta(x, x, x, x), 0, 0.850},
+		// uniform bases, two residues of each of two bases at every site:
+		// 1 bit of entropy against an expected 1.323990 for four draws
+		{"two halves", fasta(x, y, x, y), 1 / 1.323989648, 0.850},
+		// 750 columns lie ln(1.5)/ln(2) of the way from 500 to 1000
+		{"interpolated", fasta(x[:750], x[:750], x[:750], x[:750]), 0, 0.830 + 0.02*math.Log(1.5)/math.Ln2},
+		{"eight OTUs", fasta(x, x, x, x, x, x, x, x), 0, 0.811},
+	}
+	for _, tt := range tests {
+		iss, issc, err := testDB(t, tt.fasta).SaturationIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(iss-tt.iss) > 1e-6 || math.Abs(issc-tt.issc) > 1e-12 {
+			t.Errorf("%s: SaturationIndex = %g, %g, want %g, %g", tt.name, iss, issc, tt.iss, tt.issc)
+		}
+	}
+
+	for _, bad := range []string{fasta(x, y), fasta(x[:100], x[:100], x[:100], x[:100])} {
+		if _, _, err := testDB(t, bad).SaturationIndex(); err == nil {
+			t.Error("alignment outside the critical value table accepted")
+		}
+	}
+}
@@ -2,8 +2,12 @@ package gochujang
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -57,60 +61,203 @@ func ReadSeqsFromFile(path string) (seqs SequenceDB) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	first := true
-	var cname string
-	var cseq string
+	seqs.sequences, err = readFasta(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := seqs.Refresh(); err != nil {
+		log.Fatal(err)
+	}
+	return
+}
+
+func readFasta(r io.Reader) (seqs []*Sequence, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<30) // unwrapped genomes make for long lines
+	var seq *Sequence
 	for scanner.Scan() {
-		if scanner.Text()[0:1] == ">" {
-			if first {
-				first = false
-				cname = scanner.Text()[1:] // read first name
-			} else {
-				seq := NewSequence() // yield last entry
-				seq.name = cname
-				seq.sequence = cseq
-				seqs.sequences = append(seqs.sequences, seq)
-				cseq = ""
-				cname = scanner.Text()[1:] // read new name
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" {
+			continue
+		}
+		if line[0] != '>' {
+			if seq == nil {
+				return nil, errors.New("FASTA sequence data found before the first header")
 			}
-		} else {
-			cseq += scanner.Text() // concat multiple lines if present
+			// a '>' inside sequence data is the next record glued on by a
+			// concatenated file that lacked its final newline
+			gt := strings.IndexByte(line, '>')
+			if gt < 0 {
+				seq.sequence += line // concat multiple lines if present
+				continue
+			}
+			seq.sequence += line[:gt]
+			line = line[gt:]
 		}
+		seq = NewSequence() // start new entry
+		seq.name = line[1:]
+		seqs = append(seqs, seq)
 	}
-	seq := NewSequence() // get last entry
-	seq.name = cname
-	seq.sequence = cseq
-	seqs.sequences = append(seqs.sequences, seq)
-
 	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	if len(seqs) == 0 {
+		return nil, errors.New("no FASTA records found")
 	}
+	return seqs, nil
+}
 
-	for _, s := range seqs.sequences {
-		s.GuessAlphabet()
-		s.CalcBF()
+// writeFastaRecord writes one record, wrapping residues at width columns
+// (no wrapping when width is not positive).
+func writeFastaRecord(w io.Writer, header, residues string, width int) error {
+	if _, err := io.WriteString(w, ">"+header+"\n"); err != nil {
+		return err
+	}
+	if width <= 0 {
+		width = len(residues)
 	}
-	alph := seqs.sequences[0].alphabet
-	seqs.alphabet = alph
-	for _, s := range seqs.sequences {
-		if s.alphabet != alph {
-			log.Fatal("sequences are not of the same alphabet!")
-			// all seqs in DB should be of same alphabet
+	for start := 0; start < len(residues); start += width {
+		end := start + width
+		if end > len(residues) {
+			end = len(residues)
 		}
+		if _, err := io.WriteString(w, residues[start:end]+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeFastaFile rewrites a FASTA file in place with line endings and
+// stray whitespace removed, residues uppercased and wrapped at width columns
+// (unwrapped when width is not positive). The result goes to a temporary
+// file in the same directory that then replaces the original, so a failure
+// leaves the original untouched.
+func NormalizeFastaFile(path string, width int) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
 	}
-	seqs.aligned = true
-	seqlen := len(seqs.sequences[0].sequence)
-	for _, s := range seqs.sequences {
-		if len(s.sequence) != seqlen {
-			seqs.aligned = false
+	seqs, err := readFasta(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	bw := bufio.NewWriter(tmp)
+	for _, v := range seqs {
+		residues := strings.ToUpper(strings.Join(strings.Fields(v.sequence), ""))
+		if err := writeFastaRecord(bw, strings.TrimSpace(v.name), residues, width); err != nil {
+			tmp.Close()
+			return err
 		}
 	}
-	if seqs.aligned {
-		seqs.length = seqlen
+	if err := bw.Flush(); err != nil {
+		tmp.Close()
+		return err
 	}
-	seqs.CalcBF()
-	return
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+type AlphabetMismatchError struct {
+	Name     string
+	Alphabet DataType
+	Expected DataType
+}
+
+func (e *AlphabetMismatchError) Error() string {
+	return fmt.Sprintf("sequence %q has alphabet %q, expected %q", e.Name, e.Alphabet, e.Expected)
+}
+
+// Refresh rederives the alphabet, alignment state, length and BF from the
+// current sequences, so the DB stays consistent after it has been changed.
+// All sequences must share an alphabet, otherwise an AlphabetMismatchError
+// is returned and the DB is left without an alphabet.
+func (s *SequenceDB) Refresh() error {
+	s.alphabet = ""
+	s.aligned = false
+	s.length = 0
+	s.BF = nil
+	if len(s.sequences) == 0 {
+		return nil
+	}
+	for _, v := range s.sequences {
+		v.GuessAlphabet()
+		v.CalcBF()
+	}
+	alph := s.sequences[0].alphabet
+	for _, v := range s.sequences {
+		if v.alphabet != alph { // all seqs in DB should be of same alphabet
+			return &AlphabetMismatchError{Name: v.name, Alphabet: v.alphabet, Expected: alph}
+		}
+	}
+	s.alphabet = alph
+	s.setAligned()
+	s.CalcBF()
+	return nil
+}
+
+func (s *SequenceDB) setAligned() {
+	s.aligned = len(s.sequences) > 0
+	s.length = 0
+	for _, v := range s.sequences {
+		if len(v.sequence) != len(s.sequences[0].sequence) {
+			s.aligned = false
+		}
+	}
+	if s.aligned {
+		s.length = len(s.sequences[0].sequence)
+	}
+}
+
+// withSequences builds a DB out of sequences derived from s (subsets, edited
+// copies), keeping the alphabet of s rather than guessing it again.
+func (s SequenceDB) withSequences(seqs []*Sequence) SequenceDB {
+	out := SequenceDB{alphabet: s.alphabet, sequences: seqs}
+	for _, v := range seqs {
+		v.alphabet = s.alphabet
+		v.CalcBF()
+	}
+	out.setAligned()
+	out.CalcBF()
+	return out
+}
+
+func (s SequenceDB) getSequence(name string) (*Sequence, error) {
+	for _, v := range s.sequences {
+		if v.name == name {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("no sequence named %q", name)
+}
+
+func (s SequenceDB) getSequences(names []string) ([]*Sequence, error) {
+	seqs := make([]*Sequence, len(names))
+	for i, name := range names {
+		seq, err := s.getSequence(name)
+		if err != nil {
+			return nil, err
+		}
+		seqs[i] = seq
+	}
+	return seqs, nil
 }
 
 func (s Sequence) GetFasta() string {
@@ -144,6 +291,7 @@ func (s *Sequence) GuessAlphabet() {
 }
 
 func (s *Sequence) CalcBF() {
+	s.BF = nil
 	if s.alphabet == "nuc" {
 		NUCs := GetStates(s.alphabet)
 		NUCcount := make(map[string]int)
@@ -178,6 +326,7 @@ func (s *Sequence) CalcBF() {
 }
 
 func (s *SequenceDB) CalcBF() {
+	s.BF = nil
 	if s.alphabet == "nuc" {
 		NUCs := GetStates(s.alphabet)
 		NUCcount := make(map[string]int)
@@ -0,0 +1,108 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// RandomSequence draws length residues independently from the frequencies
+// bf, given in GetStates order for the alphabet. The same seed gives the
+// same sequence.
+func RandomSequence(name string, length int, bf []float64, alphabet DataType, seed int64) (*Sequence, error) {
+	states := GetStates(alphabet)
+	if len(states) == 0 {
+		return nil, fmt.Errorf("no states defined for alphabet %q", alphabet)
+	}
+	if len(bf) != len(states) {
+		return nil, fmt.Errorf("got %d frequencies, alphabet %q has %d states", len(bf), alphabet, len(states))
+	}
+	if length < 0 {
+		return nil, errors.New("length must not be negative")
+	}
+	cum := make([]float64, len(bf))
+	tot := 0.0
+	for i, f := range bf {
+		if f < 0 || math.IsNaN(f) {
+			return nil, errors.New("frequencies must be non-negative")
+		}
+		tot += f
+		cum[i] = tot
+	}
+	if math.Abs(tot-1) > 1e-3 {
+		return nil, fmt.Errorf("frequencies sum to %g, not 1", tot)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	residues := make([]byte, length)
+	for i := range residues {
+		r := rng.Float64() * tot
+		st := 0
+		for st < len(cum)-1 && r >= cum[st] {
+			st++
+		}
+		residues[i] = states[st][0]
+	}
+	seq := NewSequence()
+	seq.name = name
+	seq.sequence = string(residues)
+	seq.alphabet = alphabet
+	seq.CalcBF()
+	return seq, nil
+}
+
+const simKappa = 2.0 // transition/transversion rate ratio used by EvolveSequence under "k2p"
+
+// EvolveSequence simulates a descendant of a nucleotide sequence after
+// distance expected substitutions per site, under "jc" or "k2p" (with a
+// transition/transversion rate ratio of 2). Gaps and ambiguity codes are
+// inherited unchanged, and the descendant keeps the ancestor's name.
+func EvolveSequence(ancestor *Sequence, distance float64, model string, seed int64) (*Sequence, error) {
+	if ancestor.alphabet != Nucleotide {
+		return nil, errors.New("sequence evolution needs a nucleotide sequence")
+	}
+	if distance < 0 || math.IsNaN(distance) || math.IsInf(distance, 0) {
+		return nil, errors.New("distance must be a finite, non-negative number")
+	}
+	var pTs, pTv float64 // probability of the transition and of each transversion
+	switch model {
+	case "jc":
+		pTs = 0.25 - 0.25*math.Exp(-4*distance/3)
+		pTv = pTs
+	case "k2p":
+		beta := distance / (simKappa + 2)
+		alpha := simKappa * beta
+		pTs = 0.25 + 0.25*math.Exp(-4*beta) - 0.5*math.Exp(-2*(alpha+beta))
+		pTv = 0.25 - 0.25*math.Exp(-4*beta)
+	default:
+		return nil, fmt.Errorf("unknown substitution model %q", model)
+	}
+
+	states := GetStates(Nucleotide)
+	index := stateIndex(Nucleotide)
+	rng := rand.New(rand.NewSource(seed))
+	residues := []byte(ancestor.sequence)
+	for i, c := range residues {
+		x, ok := index[c]
+		if !ok {
+			continue
+		}
+		// A,T,G,C order: x^2 is the transition partner, x^1 and x^3 the transversions
+		r := rng.Float64()
+		switch {
+		case r < pTs:
+			residues[i] = states[x^2][0]
+		case r < pTs+pTv:
+			residues[i] = states[x^1][0]
+		case r < pTs+2*pTv:
+			residues[i] = states[x^3][0]
+		}
+	}
+	seq := NewSequence()
+	seq.name = ancestor.name
+	seq.sequence = string(residues)
+	seq.alphabet = Nucleotide
+	seq.CalcBF()
+	return seq, nil
+}
@@ -0,0 +1,124 @@
+package gochujang
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SNP is a column where some sequences carry a different residue from the
+// reference. Column is the 0-based alignment column and Position the
+// 0-based position in the ungapped reference.
+type SNP struct {
+	Column   int
+	Position int
+	Ref      byte
+	Alts     []byte            // in GetStates order
+	Carriers map[byte][]string // sequences carrying each alternate, in DB order
+}
+
+// SNPsVsReference lists the columns where any sequence has a residue other
+// than the reference's. Gaps are not alleles: columns where the reference
+// has a gap (insertions relative to it) are skipped, and a gap or
+// ambiguity code in another sequence neither makes nor joins a SNP. The
+// same goes for columns where the reference itself is ambiguous.
+func (s SequenceDB) SNPsVsReference(refName string) ([]SNP, error) {
+	if !s.aligned {
+		return nil, ErrNotAligned
+	}
+	ref, err := s.getSequence(refName)
+	if err != nil {
+		return nil, err
+	}
+	states := GetStates(s.alphabet)
+	index := stateIndex(s.alphabet)
+	var snps []SNP
+	for col, pos := range ref.ungappedPositions() {
+		if pos < 0 {
+			continue
+		}
+		r, ok := index[ref.sequence[col]]
+		if !ok {
+			continue
+		}
+		carriers := make(map[byte][]string)
+		for _, v := range s.sequences {
+			if st, ok := index[v.sequence[col]]; ok && st != r {
+				alt := states[st][0]
+				carriers[alt] = append(carriers[alt], v.name)
+			}
+		}
+		if len(carriers) == 0 {
+			continue
+		}
+		snp := SNP{Column: col, Position: pos, Ref: states[r][0], Carriers: carriers}
+		for _, st := range states {
+			if _, ok := carriers[st[0]]; ok {
+				snp.Alts = append(snp.Alts, st[0])
+			}
+		}
+		snps = append(snps, snp)
+	}
+	return snps, nil
+}
+
+// seqID is the sequence identifier in a FASTA header: its first
+// whitespace-separated field, as genome browsers and VCF tools take it.
+func seqID(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return name
+}
+
+// WriteVCF writes the SNPs against refName as a minimal VCF 4.2 file on the
+// reference's ungapped coordinates, with the first word of refName as the
+// contig. Every sequence, the reference included, is a haploid sample
+// named by the first word of its name: its GT is 0 for the reference
+// allele, the index of its alternate otherwise, and "." for a gap or
+// ambiguity code. VCF alleles are bases, so the alignment has to be
+// nucleotide.
+func (s SequenceDB) WriteVCF(w io.Writer, refName string) error {
+	if s.alphabet != Nucleotide {
+		return errors.New("VCF needs a nucleotide alignment")
+	}
+	snps, err := s.SNPsVsReference(refName)
+	if err != nil {
+		return err
+	}
+	ref, err := s.getSequence(refName)
+	if err != nil {
+		return err
+	}
+	chrom := seqID(refName)
+	index := stateIndex(s.alphabet)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "##fileformat=VCFv4.2\n##source=gochujang\n##contig=<ID=%s,length=%d>\n", chrom, len(ungapped(ref.sequence)))
+	bw.WriteString("##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n")
+	bw.WriteString("#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT")
+	for _, v := range s.sequences {
+		bw.WriteString("\t" + seqID(v.name))
+	}
+	bw.WriteString("\n")
+	for _, snp := range snps {
+		alts := make([]string, len(snp.Alts))
+		for i, a := range snp.Alts {
+			alts[i] = string(a)
+		}
+		fmt.Fprintf(bw, "%s\t%d\t.\t%c\t%s\t.\t.\t.\tGT", chrom, snp.Position+1, snp.Ref, strings.Join(alts, ","))
+		for _, v := range s.sequences {
+			gt := "."
+			if _, ok := index[v.sequence[snp.Column]]; ok {
+				gt = "0"
+				if b := upperByte(v.sequence[snp.Column]); b != snp.Ref {
+					gt = fmt.Sprint(strings.IndexByte(string(snp.Alts), b) + 1)
+				}
+			}
+			bw.WriteString("\t" + gt)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
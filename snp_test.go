@@ -0,0 +1,26 @@
+package gochujang
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteVCF(t *testing.T) {
+	db := testDB(t, ">chr1 assembled contig\nAC-GT\n>s1\nATCGT\n>s2\nAC-GN\n")
+	var buf bytes.Buffer
+	if err := db.WriteVCF(&buf, "chr1 assembled contig"); err != nil {
+		t.Fatal(err)
+	}
+	want := "##fileformat=VCFv4.2\n##source=gochujang\n##contig=<ID=chr1,length=4>\n" +
+		"##FORMAT=<ID=GT,Number=1,Type=String,Description=\"Genotype\">\n" +
+		"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tchr1\ts1\ts2\n" +
+		"chr1\t2\t.\tC\tT\t.\t.\t.\tGT\t0\t1\t0\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteVCF =\n%s\nwant\n%s", got, want)
+	}
+
+	prot := testDB(t, ">p1\nMKV\n>p2\nMRV\n")
+	if err := prot.WriteVCF(&buf, "p1"); err == nil {
+		t.Error("protein alignment written as VCF")
+	}
+}
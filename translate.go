@@ -0,0 +1,158 @@
+package gochujang
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type GeneticCode struct {
+	ID   int
+	Name string
+	aas  string // amino acid for each codon, codons ordered TTT, TTC, TTA, TTG, TCT, ...
+}
+
+// NCBI translation tables, see https://www.ncbi.nlm.nih.gov/Taxonomy/Utils/wprintgc.cgi
+var geneticCodes = map[int]*GeneticCode{
+	1:  {1, "Standard", "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	2:  {2, "Vertebrate Mitochondrial", "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSS**VVVVAAAADDEEGGGG"},
+	3:  {3, "Yeast Mitochondrial", "FFLLSSSSYY**CCWWTTTTPPPPHHQQRRRRIIMMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	4:  {4, "Mold, Protozoan, and Coelenterate Mitochondrial and Mycoplasma/Spiroplasma", "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	5:  {5, "Invertebrate Mitochondrial", "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSSSVVVVAAAADDEEGGGG"},
+	6:  {6, "Ciliate, Dasycladacean and Hexamita Nuclear", "FFLLSSSSYYQQCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	9:  {9, "Echinoderm and Flatworm Mitochondrial", "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG"},
+	10: {10, "Euplotid Nuclear", "FFLLSSSSYY**CCCWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	11: {11, "Bacterial, Archaeal and Plant Plastid", "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	12: {12, "Alternative Yeast Nuclear", "FFLLSSSSYY**CC*WLLLSPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	13: {13, "Ascidian Mitochondrial", "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSGGVVVVAAAADDEEGGGG"},
+	14: {14, "Alternative Flatworm Mitochondrial", "FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG"},
+	16: {16, "Chlorophycean Mitochondrial", "FFLLSSSSYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	21: {21, "Trematode Mitochondrial", "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNNKSSSSVVVVAAAADDEEGGGG"},
+	22: {22, "Scenedesmus obliquus Mitochondrial", "FFLLSS*SYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+	23: {23, "Thraustochytrium Mitochondrial", "FF*LSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"},
+}
+
+func GetGeneticCode(table int) (*GeneticCode, error) {
+	code, exists := geneticCodes[table]
+	if !exists {
+		return nil, fmt.Errorf("unknown genetic code table %d", table)
+	}
+	return code, nil
+}
+
+func codonIndex(codon string) int { // position of a codon in TCAG order, -1 if not a concrete codon
+	if len(codon) != 3 {
+		return -1
+	}
+	idx := 0
+	for i := 0; i < 3; i++ {
+		var b int
+		switch codon[i] {
+		case 'T', 't', 'U', 'u':
+			b = 0
+		case 'C', 'c':
+			b = 1
+		case 'A', 'a':
+			b = 2
+		case 'G', 'g':
+			b = 3
+		default:
+			return -1
+		}
+		idx = idx*4 + b
+	}
+	return idx
+}
+
+// TranslateCodon returns the amino acid for a codon, '*' for a stop, '-' for
+// a gap codon and 'X' for anything ambiguous.
+func (g *GeneticCode) TranslateCodon(codon string) byte {
+	if codon == "---" {
+		return '-'
+	}
+	idx := codonIndex(codon)
+	if idx < 0 {
+		return 'X'
+	}
+	return g.aas[idx]
+}
+
+func (g *GeneticCode) IsStop(codon string) bool {
+	idx := codonIndex(codon)
+	return idx >= 0 && g.aas[idx] == '*'
+}
+
+func (g *GeneticCode) translate(residues string, frame int) string {
+	var prot strings.Builder
+	for i := frame; i+3 <= len(residues); i += 3 {
+		prot.WriteByte(g.TranslateCodon(residues[i : i+3]))
+	}
+	return prot.String()
+}
+
+// Translate translates the sequence in frame 0 under the given NCBI table; a
+// trailing partial codon is dropped.
+func (s Sequence) Translate(table int) (*Sequence, error) {
+	if s.alphabet != Nucleotide {
+		return nil, fmt.Errorf("cannot translate %q, not a nucleotide sequence", s.name)
+	}
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return nil, err
+	}
+	prot := NewSequence()
+	prot.name = s.name
+	prot.sequence = code.translate(s.sequence, 0)
+	prot.alphabet = AminoAcid
+	prot.CalcBF()
+	return prot, nil
+}
+
+func internalStops(prot string) int {
+	return strings.Count(strings.TrimSuffix(prot, "*"), "*")
+}
+
+// isNucleotideCode reports whether residues hold only gaps and IUPAC
+// nucleotide codes (U included), in either case.
+func isNucleotideCode(residues string) bool {
+	for i := 0; i < len(residues); i++ {
+		if _, ok := iupacBases[upperByte(residues[i])]; !ok && !isGap(residues[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// TranslateFile translates every nucleotide record of a FASTA file and
+// writes the proteins as FASTA. Each record is read in whichever forward
+// frame gives the fewest internal stops (frame 0 on ties), and the frame and
+// number of internal stops are appended to its header. Lowercase and IUPAC
+// codes are accepted; codons with ambiguity codes translate to X.
+func TranslateFile(in io.Reader, out io.Writer, table int) error {
+	code, err := GetGeneticCode(table)
+	if err != nil {
+		return err
+	}
+	records, err := readFasta(in)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if !isNucleotideCode(rec.sequence) {
+			return fmt.Errorf("cannot translate %q, not a nucleotide sequence", rec.name)
+		}
+		cds := ungapped(rec.sequence)
+		bestFrame, bestProt := 0, code.translate(cds, 0)
+		for frame := 1; frame < 3; frame++ {
+			prot := code.translate(cds, frame)
+			if internalStops(prot) < internalStops(bestProt) {
+				bestFrame, bestProt = frame, prot
+			}
+		}
+		header := fmt.Sprintf("%s frame=%d internal_stops=%d", rec.name, bestFrame, internalStops(bestProt))
+		if err := writeFastaRecord(out, header, bestProt, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package gochujang
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranslateFile(t *testing.T) {
+	in := ">soft\natggcttaa\n>iupac\nATGRCTTAA\n>rna\nAUGGCUUAA\n"
+	var out strings.Builder
+	if err := TranslateFile(strings.NewReader(in), &out, 1); err != nil {
+		t.Fatal(err)
+	}
+	want := ">soft frame=0 internal_stops=0\nMA*\n" +
+		">iupac frame=0 internal_stops=0\nMX*\n" +
+		">rna frame=0 internal_stops=0\nMA*\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+
+	err := TranslateFile(strings.NewReader(">prot\nMKLV\n"), &out, 1)
+	if err == nil {
+		t.Error("protein input should be rejected")
+	}
+}
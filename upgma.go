@@ -0,0 +1,122 @@
+package gochujang
+
+import "errors"
+
+// UPGMA builds an ultrametric tree from the DB's distance matrix under the
+// given model. Tips are labelled with sequence names and branch lengths are
+// in units of distance/2.
+func (s SequenceDB) UPGMA(model string) (*Node, error) {
+	if len(s.sequences) == 0 {
+		return nil, errors.New("no sequences to cluster")
+	}
+	dist, err := s.DistanceMatrixStrict(model, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(s.sequences)
+	nodes := make([]*Node, n)
+	sizes := make([]int, n)
+	heights := make([]float64, n)
+	active := make([]int, n) // matrix rows still standing for a cluster
+	for i, v := range s.sequences {
+		nodes[i] = NewNode()
+		nodes[i].label = v.name
+		nodes[i].istip = true
+		nodes[i].number = i
+		sizes[i] = 1
+		active[i] = i
+	}
+
+	for next := n; len(active) > 1; next++ {
+		bi, bj := 0, 1
+		for x := 0; x < len(active); x++ {
+			for y := x + 1; y < len(active); y++ {
+				if dist[active[x]][active[y]] < dist[active[bi]][active[bj]] {
+					bi, bj = x, y
+				}
+			}
+		}
+		i, j := active[bi], active[bj]
+		h := dist[i][j] / 2
+
+		parent := NewNode()
+		parent.number = next
+		for _, c := range []int{i, j} {
+			nodes[c].parent = parent
+			nodes[c].length = h - heights[c]
+			parent.children = append(parent.children, nodes[c])
+		}
+		for _, k := range active { // average linkage, merged cluster takes row i
+			if k != i && k != j {
+				d := (dist[i][k]*float64(sizes[i]) + dist[j][k]*float64(sizes[j])) / float64(sizes[i]+sizes[j])
+				dist[i][k] = d
+				dist[k][i] = d
+			}
+		}
+		nodes[i] = parent
+		sizes[i] += sizes[j]
+		heights[i] = h
+		active = append(active[:bj], active[bj+1:]...)
+	}
+	return nodes[active[0]], nil
+}
+
+func (n *Node) height() float64 { // ultrametric, so any path down to a tip will do
+	h := 0.0
+	for cur := n; !cur.istip; cur = cur.children[0] {
+		h += cur.children[0].length
+	}
+	return h
+}
+
+func (n *Node) tipLabels() (labels []string) {
+	if n.istip {
+		return []string{n.label}
+	}
+	for _, c := range n.children {
+		labels = append(labels, c.tipLabels()...)
+	}
+	return
+}
+
+// ClusterUPGMA cuts the UPGMA dendrogram at a distance threshold and returns
+// the cluster id of every sequence: clusters whose average distance (twice
+// the node height) is at most threshold are kept whole. Ids are numbered
+// from 0 in DB order.
+func (s SequenceDB) ClusterUPGMA(threshold float64, model string) (map[string]int, error) {
+	root, err := s.UPGMA(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups [][]string
+	var cut func(n *Node)
+	cut = func(n *Node) {
+		if n.istip || 2*n.height() <= threshold {
+			groups = append(groups, n.tipLabels())
+			return
+		}
+		for _, c := range n.children {
+			cut(c)
+		}
+	}
+	cut(root)
+
+	group := make(map[string]int)
+	for g, labels := range groups {
+		for _, l := range labels {
+			group[l] = g
+		}
+	}
+	clusters := make(map[string]int)
+	ids := make(map[int]int) // renumber groups by first appearance in the DB
+	for _, v := range s.sequences {
+		g := group[v.name]
+		if _, seen := ids[g]; !seen {
+			ids[g] = len(ids)
+		}
+		clusters[v.name] = ids[g]
+	}
+	return clusters, nil
+}
@@ -0,0 +1,139 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CharacterConsistency reports whether every sequence uses the same set of
+// characters. If not, perSeq lists, for each sequence that has any, the
+// characters it uses that at least one other sequence does not.
+func (s SequenceDB) CharacterConsistency() (consistent bool, perSeq map[string][]string) {
+	charSets := make([]map[byte]bool, len(s.sequences))
+	shared := make(map[byte]int) // number of sequences using each character
+	for i, v := range s.sequences {
+		charSets[i] = make(map[byte]bool)
+		for j := 0; j < len(v.sequence); j++ {
+			charSets[i][v.sequence[j]] = true
+		}
+		for c := range charSets[i] {
+			shared[c]++
+		}
+	}
+
+	for i, v := range s.sequences {
+		var odd []string
+		for c := range charSets[i] {
+			if shared[c] < len(s.sequences) {
+				odd = append(odd, string(c))
+			}
+		}
+		if len(odd) > 0 {
+			if perSeq == nil {
+				perSeq = make(map[string][]string)
+			}
+			sort.Strings(odd)
+			perSeq[v.name] = odd
+		}
+	}
+	return perSeq == nil, perSeq
+}
+
+// AuditIssueType names the kind of problem an Audit found.
+type AuditIssueType string
+
+const (
+	IllegalCharacter   AuditIssueType = "illegal character"
+	AllGap             AuditIssueType = "all gap"
+	DuplicateName      AuditIssueType = "duplicate name"
+	InternalStop       AuditIssueType = "internal stop"
+	ExcessiveAmbiguity AuditIssueType = "excessive ambiguity"
+)
+
+// AuditIssue is one problem found in one sequence.
+type AuditIssue struct {
+	Name    string
+	Type    AuditIssueType
+	Details string
+}
+
+const maxAmbiguousFraction = 0.5 // of the non-gap characters
+
+// Audit checks every sequence and collects all the problems found instead
+// of stopping at the first: characters outside the alphabet (IUPAC codes
+// allowed), all-gap sequences, repeated names, more than half of the non-gap
+// characters ambiguous and, when every sequence is nucleotide with an
+// ungapped length divisible by three, internal stops under the standard
+// code.
+func (s SequenceDB) Audit() ([]AuditIssue, error) {
+	if len(s.sequences) == 0 {
+		return nil, errors.New("no sequences to audit")
+	}
+	legal := func(c byte) bool {
+		_, ok := iupacBases[c]
+		return ok || c == '?'
+	}
+	if s.alphabet != Nucleotide {
+		legal = func(c byte) bool {
+			return strings.IndexByte("ACDEFGHIKLMNPQRSTVWYBZJUOX*?", c) >= 0
+		}
+	}
+	index := stateIndex(s.alphabet)
+
+	cds := s.alphabet == Nucleotide
+	for _, v := range s.sequences {
+		if len(ungapped(v.sequence))%3 != 0 {
+			cds = false
+		}
+	}
+	code, err := GetGeneticCode(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []AuditIssue
+	seen := make(map[string]bool)
+	for _, v := range s.sequences {
+		if seen[v.name] {
+			issues = append(issues, AuditIssue{v.name, DuplicateName, "name used by an earlier sequence"})
+		}
+		seen[v.name] = true
+
+		illegal := make(map[byte]bool)
+		var chars []string
+		residues, ambiguous := 0, 0
+		for i := 0; i < len(v.sequence); i++ {
+			c := v.sequence[i]
+			if isGap(c) {
+				continue
+			}
+			residues++
+			if _, ok := index[c]; !ok {
+				ambiguous++
+			}
+			if !legal(upperByte(c)) && !illegal[c] {
+				illegal[c] = true
+				chars = append(chars, string(c))
+			}
+		}
+		if len(chars) > 0 {
+			sort.Strings(chars)
+			issues = append(issues, AuditIssue{v.name, IllegalCharacter, "characters " + strings.Join(chars, " ")})
+		}
+		if residues == 0 {
+			issues = append(issues, AuditIssue{v.name, AllGap, "no residues"})
+			continue
+		}
+		if frac := float64(ambiguous) / float64(residues); frac > maxAmbiguousFraction {
+			issues = append(issues, AuditIssue{v.name, ExcessiveAmbiguity, fmt.Sprintf("%.1f%% of residues ambiguous", 100*frac)})
+		}
+		if cds {
+			if stops := internalStops(code.translate(ungapped(v.sequence), 0)); stops > 0 {
+				issues = append(issues, AuditIssue{v.name, InternalStop, fmt.Sprintf("internal stop codons: %d", stops)})
+			}
+		}
+	}
+	return issues, nil
+}
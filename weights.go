@@ -0,0 +1,79 @@
+package gochujang
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HenikoffWeights gives each sequence its Henikoff & Henikoff (1994)
+// position-based weight: every column hands each of its residues
+// 1/(distinct residues x copies of that residue), and the sums are
+// normalised to add up to one. Gaps and ambiguity codes receive nothing.
+func (s SequenceDB) HenikoffWeights() (map[string]float64, error) {
+	counts, err := s.columnStateCounts()
+	if err != nil {
+		return nil, err
+	}
+	index := stateIndex(s.alphabet)
+	raw := make([]float64, len(s.sequences))
+	for pos, col := range counts {
+		distinct := 0
+		for _, c := range col {
+			if c > 0 {
+				distinct++
+			}
+		}
+		for i, v := range s.sequences {
+			if st, ok := index[v.sequence[pos]]; ok {
+				raw[i] += 1 / float64(distinct*col[st])
+			}
+		}
+	}
+	tot := 0.0
+	for _, w := range raw {
+		tot += w
+	}
+	if tot == 0 {
+		return nil, errors.New("alignment has no residues to weight")
+	}
+	weights := make(map[string]float64, len(s.sequences))
+	for i, v := range s.sequences {
+		weights[v.name] = raw[i] / tot
+	}
+	return weights, nil
+}
+
+// WeightedBF is the DB-level base frequencies (GetStates order) with every
+// sequence's residues counted weights[name] times, e.g. with the output of
+// HenikoffWeights.
+func (s SequenceDB) WeightedBF(weights map[string]float64) ([]float64, error) {
+	states := GetStates(s.alphabet)
+	if len(states) == 0 {
+		return nil, fmt.Errorf("no states defined for alphabet %q", s.alphabet)
+	}
+	index := stateIndex(s.alphabet)
+	bf := make([]float64, len(states))
+	tot := 0.0
+	for _, v := range s.sequences {
+		w, ok := weights[v.name]
+		if !ok {
+			return nil, fmt.Errorf("no weight for sequence %q", v.name)
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("negative weight for sequence %q", v.name)
+		}
+		for i := 0; i < len(v.sequence); i++ {
+			if st, ok := index[v.sequence[i]]; ok {
+				bf[st] += w
+				tot += w
+			}
+		}
+	}
+	if tot == 0 {
+		return nil, errors.New("no weighted residues")
+	}
+	for i := range bf {
+		bf[i] /= tot
+	}
+	return bf, nil
+}